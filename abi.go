@@ -0,0 +1,301 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"fmt"
+	"github.com/orbs-network/gamma-cli/jsoncodec"
+	"github.com/pkg/errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"strings"
+)
+
+const SDK_EXPORT_FUNCTION = "Export"
+
+var abiExportVisibilities = []string{"PUBLIC", "SYSTEM", "EVENTS"}
+
+func commandContractAbi(requiredOptions []string) {
+	codeFile := requiredOptions[0]
+
+	abi := loadContractAbi(codeFile)
+
+	output, err := jsoncodec.MarshalContractAbi(abi)
+	if err != nil {
+		die("Could not encode contract abi to json.\n\n%s", err.Error())
+	}
+
+	log("%s\n", string(output))
+}
+
+func commandGenerateCall(requiredOptions []string) {
+	abiFile := requiredOptions[0]
+	methodName := requiredOptions[1]
+
+	abi := loadContractAbi(abiFile)
+	method := abi.FindMethod(methodName)
+	if method == nil {
+		die("Method '%s' is not exported by contract '%s'.\n\nExported methods are: %s", methodName, abi.ContractName, strings.Join(abiMethodNames(abi), " "))
+	}
+
+	sendTx := &jsoncodec.SendTx{
+		ContractName: abi.ContractName,
+		MethodName:   method.Name,
+		Arguments:    []*jsoncodec.Arg{},
+	}
+	for _, arg := range method.Arguments {
+		var value interface{} = ""
+		if strings.HasSuffix(arg.Type, "Array") {
+			value = []string{}
+		}
+		sendTx.Arguments = append(sendTx.Arguments, &jsoncodec.Arg{Type: arg.Type, Value: value})
+	}
+
+	output, err := jsoncodec.MarshalSendTx(sendTx)
+	if err != nil {
+		die("Could not encode call to json.\n\n%s", err.Error())
+	}
+
+	log("%s\n", string(output))
+}
+
+func validateInputAgainstAbi(contractName string, methodName string, args []*jsoncodec.Arg) {
+	if *flagAbi == "" {
+		return
+	}
+
+	// the call names the deployed contract, the abi the logical one (eg. of its source file) which resolves the same way
+	abi := loadContractAbi(*flagAbi)
+	abi.ContractName = resolveContractAlias(abi.ContractName)
+	if err := abi.ValidateCall(contractName, methodName, args); err != nil {
+		die(err.Error())
+	}
+}
+
+// accepts a json abi file (output of contract-abi) or contract source code (file or dir)
+func loadContractAbi(path string) *jsoncodec.ContractAbi {
+	if strings.HasSuffix(path, ".json") {
+		bytes, err := ioutil.ReadFile(path)
+		if err != nil {
+			die("Could not open abi file '%s'.\n\n%s", path, err.Error())
+		}
+
+		abi, err := jsoncodec.UnmarshalContractAbi(bytes)
+		if err != nil {
+			die("Failed parsing abi json file '%s'.\n\n%s", path, err.Error())
+		}
+		if *flagContractName != "" {
+			abi.ContractName = *flagContractName
+		}
+		return abi
+	}
+
+	filenames, err := _getSourceFilenames(path)
+	if err != nil {
+		die("Could not find path\n\n%s", err.Error())
+	}
+	code, err := _getSource(path)
+	if err != nil {
+		die("Could not read contract source '%s'.\n\n%s", path, err.Error())
+	}
+
+	contractName := *flagContractName
+	if contractName == "" {
+		contractName = getFilenameWithoutExtension(path)
+	}
+
+	abi, err := parseContractAbi(contractName, filenames, code)
+	if err != nil {
+		die("Could not extract abi from contract source '%s'.\n\n%s", path, err.Error())
+	}
+	return abi
+}
+
+// filenames are only used to report parse errors
+func parseContractAbi(contractName string, filenames []string, sources [][]byte) (*jsoncodec.ContractAbi, error) {
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for i, source := range sources {
+		file, err := parser.ParseFile(fset, filenames[i], source, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return contractAbiFromFiles(fset, contractName, files)
+}
+
+// extracts the functions listed in sdk.Export(...) of PUBLIC, SYSTEM and EVENTS from contract source
+func contractAbiFromFiles(fset *token.FileSet, contractName string, files []*ast.File) (*jsoncodec.ContractAbi, error) {
+	functions := make(map[string]*ast.FuncDecl)
+	exports := make(map[string][]*ast.Ident)
+
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					functions[decl.Name.Name] = decl
+				}
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					valueSpec, ok := spec.(*ast.ValueSpec)
+					if !ok {
+						continue
+					}
+					for j, name := range valueSpec.Names {
+						if j < len(valueSpec.Values) && isAbiExportVisibility(name.Name) {
+							exports[name.Name] = append(exports[name.Name], exportedFunctionIdents(valueSpec.Values[j])...)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	abi := &jsoncodec.ContractAbi{
+		ContractName: contractName,
+		Methods:      []*jsoncodec.AbiMethod{},
+		Events:       []*jsoncodec.AbiMethod{},
+	}
+	for _, visibility := range abiExportVisibilities {
+		for _, ident := range exports[visibility] {
+			function, found := functions[ident.Name]
+			if !found {
+				return nil, errors.Errorf("%s: function '%s' exported in %s is not declared", fset.Position(ident.Pos()), ident.Name, visibility)
+			}
+
+			method, err := abiMethodFromFunction(fset, visibility, function)
+			if err != nil {
+				return nil, err
+			}
+
+			if visibility == "EVENTS" {
+				abi.Events = append(abi.Events, method)
+			} else {
+				abi.Methods = append(abi.Methods, method)
+			}
+		}
+	}
+	return abi, nil
+}
+
+func isAbiExportVisibility(name string) bool {
+	for _, visibility := range abiExportVisibilities {
+		if name == visibility {
+			return true
+		}
+	}
+	return false
+}
+
+// returns the function identifiers in an expression of the form sdk.Export(a, b, c)
+func exportedFunctionIdents(expr ast.Expr) []*ast.Ident {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || selector.Sel.Name != SDK_EXPORT_FUNCTION {
+		return nil
+	}
+
+	var res []*ast.Ident
+	for _, arg := range call.Args {
+		if ident, ok := arg.(*ast.Ident); ok {
+			res = append(res, ident)
+		}
+	}
+	return res
+}
+
+func abiMethodFromFunction(fset *token.FileSet, visibility string, function *ast.FuncDecl) (*jsoncodec.AbiMethod, error) {
+	method := &jsoncodec.AbiMethod{
+		Name:       function.Name.Name,
+		Visibility: visibility,
+		Arguments:  []*jsoncodec.AbiArg{},
+		Returns:    []string{},
+	}
+
+	for _, field := range function.Type.Params.List {
+		argType := abiTypeFromExpr(field.Type)
+		if argType == "" {
+			return nil, errors.Errorf("%s: function '%s' has an argument of unsupported type '%s'", fset.Position(field.Pos()), method.Name, types.ExprString(field.Type))
+		}
+		if len(field.Names) == 0 {
+			method.Arguments = append(method.Arguments, &jsoncodec.AbiArg{Name: fmt.Sprintf("arg%d", len(method.Arguments)+1), Type: argType})
+		}
+		for _, name := range field.Names {
+			method.Arguments = append(method.Arguments, &jsoncodec.AbiArg{Name: name.Name, Type: argType})
+		}
+	}
+
+	if function.Type.Results != nil {
+		for _, field := range function.Type.Results.List {
+			returnType := abiTypeFromExpr(field.Type)
+			if returnType == "" {
+				return nil, errors.Errorf("%s: function '%s' returns an unsupported type '%s'", fset.Position(field.Pos()), method.Name, types.ExprString(field.Type))
+			}
+			for i := 0; i < len(field.Names) || i == 0; i++ {
+				method.Returns = append(method.Returns, returnType)
+			}
+		}
+	}
+
+	return method, nil
+}
+
+// maps a go type expression to the jsoncodec type name, returns "" if the type is unsupported
+func abiTypeFromExpr(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		switch expr.Name {
+		case "uint32", "uint64", "string", "bool":
+			return expr.Name
+		}
+	case *ast.StarExpr:
+		if types.ExprString(expr.X) == "big.Int" {
+			return "uint256"
+		}
+	case *ast.ArrayType:
+		if isByteIdent(expr.Elt) {
+			if expr.Len == nil {
+				return "bytes"
+			}
+			switch types.ExprString(expr.Len) {
+			case "20":
+				return "bytes20"
+			case "32":
+				return "bytes32"
+			}
+			return ""
+		}
+		if expr.Len == nil {
+			elemType := abiTypeFromExpr(expr.Elt)
+			if elemType != "" && !strings.HasSuffix(elemType, "Array") {
+				return elemType + "Array"
+			}
+		}
+	}
+	return ""
+}
+
+func isByteIdent(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && (ident.Name == "byte" || ident.Name == "uint8")
+}
+
+func abiMethodNames(abi *jsoncodec.ContractAbi) []string {
+	var res []string
+	for _, method := range abi.Methods {
+		res = append(res, method.Name)
+	}
+	return res
+}
@@ -0,0 +1,98 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"github.com/orbs-network/gamma-cli/jsoncodec"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+const abiTestContract = `package main
+
+import (
+	"github.com/orbs-network/orbs-contract-sdk/go/sdk/v1"
+	"math/big"
+)
+
+var PUBLIC = sdk.Export(transfer, balanceOf)
+var SYSTEM = sdk.Export(_init)
+var EVENTS = sdk.Export(Transferred)
+
+func Transferred(from []byte, to []byte, amount *big.Int) {}
+
+func _init() {}
+
+func transfer(to []byte, amount uint64, hash [32]byte) {}
+
+func balanceOf(owners [][20]byte) (uint64, []string) {
+	return 0, nil
+}
+
+func notExported(x float64) {}
+`
+
+func TestParseContractAbi(t *testing.T) {
+	abi, err := parseContractAbi("MyToken", []string{"MyToken.go"}, [][]byte{[]byte(abiTestContract)})
+	require.NoError(t, err, "parse abi should not fail")
+
+	require.Equal(t, &jsoncodec.ContractAbi{
+		ContractName: "MyToken",
+		Methods: []*jsoncodec.AbiMethod{
+			{Name: "transfer", Visibility: "PUBLIC", Arguments: []*jsoncodec.AbiArg{{Name: "to", Type: "bytes"}, {Name: "amount", Type: "uint64"}, {Name: "hash", Type: "bytes32"}}, Returns: []string{}},
+			{Name: "balanceOf", Visibility: "PUBLIC", Arguments: []*jsoncodec.AbiArg{{Name: "owners", Type: "bytes20Array"}}, Returns: []string{"uint64", "stringArray"}},
+			{Name: "_init", Visibility: "SYSTEM", Arguments: []*jsoncodec.AbiArg{}, Returns: []string{}},
+		},
+		Events: []*jsoncodec.AbiMethod{
+			{Name: "Transferred", Visibility: "EVENTS", Arguments: []*jsoncodec.AbiArg{{Name: "from", Type: "bytes"}, {Name: "to", Type: "bytes"}, {Name: "amount", Type: "uint256"}}, Returns: []string{}},
+		},
+	}, abi)
+}
+
+func TestParseContractAbi_UnsupportedType(t *testing.T) {
+	source := `package main
+var PUBLIC = sdk.Export(average)
+func average(values []float64) float64 { return 0 }
+`
+	_, err := parseContractAbi("Average", []string{"Average.go"}, [][]byte{[]byte(source)})
+	require.Error(t, err, "parse abi of unsupported type should fail")
+}
+
+func TestParseContractAbi_UndeclaredExport(t *testing.T) {
+	source := `package main
+var PUBLIC = sdk.Export(missing)
+`
+	_, err := parseContractAbi("Missing", []string{"Missing.go"}, [][]byte{[]byte(source)})
+	require.Error(t, err, "parse abi of undeclared function should fail")
+}
+
+func TestParseContractAbi_ErrorNamesFile(t *testing.T) {
+	_, err := parseContractAbi("Broken", []string{"contracts/a.go", "contracts/b.go"}, [][]byte{[]byte("package main\n"), []byte("package main\nfunc {\n")})
+	require.Error(t, err, "parse abi of invalid source should fail")
+	require.Contains(t, err.Error(), "contracts/b.go:2:", "error should name the file it is in")
+}
+
+func TestValidateInputAgainstAbi_ResolvedAlias(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gamma-cli-abi")
+	require.NoError(t, err, "temp dir should be created")
+	defer os.RemoveAll(dir)
+
+	abiFile := path.Join(dir, "MyToken.go")
+	require.NoError(t, ioutil.WriteFile(abiFile, []byte(abiTestContract), 0644))
+
+	prevAbi, prevLock, prevAliases := *flagAbi, *flagDeployLock, envContractAliases
+	defer func() { *flagAbi, *flagDeployLock, envContractAliases = prevAbi, prevLock, prevAliases }()
+	*flagAbi = abiFile
+	*flagDeployLock = path.Join(dir, DEPLOY_LOCK_FILENAME)
+	envContractAliases = map[string]string{"MyToken": "MyToken_v2"}
+
+	// fails the test process on mismatch
+	validateInputAgainstAbi(resolveContractAlias("MyToken"), "balanceOf", []*jsoncodec.Arg{{Type: "bytes20Array", Value: []interface{}{}}})
+}
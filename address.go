@@ -0,0 +1,180 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"github.com/orbs-network/crypto-lib-go/crypto/digest"
+	"github.com/orbs-network/crypto-lib-go/crypto/encoding"
+	"github.com/orbs-network/gamma-cli/jsoncodec"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+const ADDRESS_SIZE_BYTES = digest.CLIENT_ADDRESS_SIZE_BYTES
+
+func commandAddress(requiredOptions []string) {
+	subcommand := requiredOptions[0]
+	args := requiredOptions[1:]
+
+	switch subcommand {
+	case "add":
+		requireSubcommandArgs("address add", args, "<NAME>", "<ADDRESS>")
+		commandAddressAdd(args[0], args[1])
+	case "list":
+		commandAddressList()
+	case "remove":
+		requireSubcommandArgs("address remove", args, "<NAME>")
+		commandAddressRemove(args[0])
+	case "from-public-key":
+		requireSubcommandArgs("address from-public-key", args, "<PUBLIC_KEY>")
+		commandAddressFromPublicKey(args[0])
+	case "validate":
+		requireSubcommandArgs("address validate", args, "<ADDRESS>")
+		commandAddressValidate(args[0])
+	default:
+		die("Unknown address subcommand '%s'.\n\nSupported subcommands are: add list remove from-public-key validate", subcommand)
+	}
+}
+
+func commandAddressAdd(name string, address string) {
+	rawAddress, err := decodeAddress(address)
+	if err != nil {
+		die("Could not add '%s' to address book.\n\n%s", name, err.Error())
+	}
+
+	book := readAddressBook()
+	if existing, found := book[name]; found {
+		log("Replacing existing address %s of '%s'.", existing, name)
+	}
+	book[name] = encoding.EncodeHex(rawAddress)
+	writeAddressBook(book)
+
+	log("Address %s added to address book '%s' as '%s'.", book[name], *flagAddressBook, name)
+}
+
+func commandAddressList() {
+	book := readAddressBook()
+	if len(book) == 0 {
+		log("Address book '%s' is empty.", *flagAddressBook)
+		return
+	}
+
+	names := make([]string, 0, len(book))
+	for name := range book {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		rawAddress, err := decodeAddress(book[name])
+		if err != nil {
+			log("%-20s %s (invalid: %s)", name, book[name], err.Error())
+			continue
+		}
+		log("%-20s %s", name, encoding.EncodeHex(rawAddress))
+	}
+}
+
+func commandAddressRemove(name string) {
+	book := readAddressBook()
+	if _, found := book[name]; !found {
+		die("Address with name '%s' not found in address book '%s'.", name, *flagAddressBook)
+	}
+	delete(book, name)
+	writeAddressBook(book)
+
+	log("Address '%s' removed from address book '%s'.", name, *flagAddressBook)
+}
+
+func commandAddressFromPublicKey(publicKey string) {
+	rawPublicKey, err := encoding.DecodeHex(publicKey)
+	if err != nil {
+		die("Could not parse public key hex string '%s'.\n\n%s", publicKey, err.Error())
+	}
+
+	rawAddress, err := digest.CalcClientAddressOfEd25519PublicKey(rawPublicKey)
+	if err != nil {
+		die("Could not calculate address of public key '%s'.\n\n%s", publicKey, err.Error())
+	}
+
+	log("%s", encoding.EncodeHex(rawAddress))
+}
+
+func commandAddressValidate(address string) {
+	rawAddress, err := decodeAddress(address)
+	if err != nil {
+		die("Address '%s' is invalid.\n\n%s", address, err.Error())
+	}
+
+	checksumAddress := encoding.EncodeHex(rawAddress)
+	if strings.TrimPrefix(address, "0x") != checksumAddress[2:] {
+		log("Address is valid but not checksum encoded, checksum form is:\n%s", checksumAddress)
+		return
+	}
+
+	log("Address is valid with correct checksum:\n%s", checksumAddress)
+}
+
+// accepts checksum encoded hex or uniform case (all lower/upper) hex of the correct size
+func decodeAddress(address string) ([]byte, error) {
+	rawAddress, err := encoding.DecodeHex(address)
+	if err != nil {
+		return nil, err
+	}
+	if len(rawAddress) != ADDRESS_SIZE_BYTES {
+		return nil, errors.Errorf("address should be %d bytes (%d hexes), actual size: %d", ADDRESS_SIZE_BYTES, ADDRESS_SIZE_BYTES*2, len(rawAddress))
+	}
+	return rawAddress, nil
+}
+
+func getAddressFromBook(name string) []byte {
+	book := readAddressBook()
+
+	address, found := book[name]
+	if !found {
+		die("Address with name '%s' not found in address book '%s'.", name, *flagAddressBook)
+	}
+
+	rawAddress, err := decodeAddress(address)
+	if err != nil {
+		die("Address '%s' of '%s' in address book '%s' is invalid.\n\n%s", address, name, *flagAddressBook, err.Error())
+	}
+
+	return rawAddress
+}
+
+func readAddressBook() jsoncodec.AddressBook {
+	if !doesFileExist(*flagAddressBook) {
+		return make(jsoncodec.AddressBook)
+	}
+
+	bytes, err := ioutil.ReadFile(*flagAddressBook)
+	if err != nil {
+		die("Could not open address book file '%s'.\n\n%s", *flagAddressBook, err.Error())
+	}
+
+	book, err := jsoncodec.UnmarshalAddressBook(bytes)
+	if err != nil {
+		die("Failed parsing address book json file '%s'.\n\n%s", *flagAddressBook, err.Error())
+	}
+
+	return book
+}
+
+func writeAddressBook(book jsoncodec.AddressBook) {
+	bytes, err := jsoncodec.MarshalAddressBook(book)
+	if err != nil {
+		die("Could not encode address book to json.\n\n%s", err.Error())
+	}
+
+	err = ioutil.WriteFile(*flagAddressBook, bytes, 0644)
+	if err != nil {
+		die("Could not write address book to file '%s'.\n\n%s", *flagAddressBook, err.Error())
+	}
+}
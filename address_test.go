@@ -0,0 +1,130 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"github.com/orbs-network/crypto-lib-go/crypto/encoding"
+	"github.com/orbs-network/gamma-cli/jsoncodec"
+	"github.com/orbs-network/orbs-client-sdk-go/orbs"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+const (
+	checksumAddress  = "0x5B63Ca66637316A0D7f84Ebf60E50963c10059aD"
+	lowercaseAddress = "0x5b63ca66637316a0d7f84ebf60e50963c10059ad"
+)
+
+func captureStdout(t *testing.T, f func()) string {
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err, "pipe should be created")
+	prevStdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = prevStdout }()
+
+	f()
+	writer.Close()
+	output, err := ioutil.ReadAll(reader)
+	require.NoError(t, err, "output should be read")
+	return string(output)
+}
+
+func useTempAddressBook(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "gamma-cli-address")
+	require.NoError(t, err, "temp dir should be created")
+	prevBook := *flagAddressBook
+	*flagAddressBook = path.Join(dir, ADDRESS_BOOK_FILENAME)
+	return func() {
+		*flagAddressBook = prevBook
+		os.RemoveAll(dir)
+	}
+}
+
+func TestDecodeAddress(t *testing.T) {
+	tests := []struct {
+		name      string
+		address   string
+		expectErr bool
+	}{
+		{"Checksum", checksumAddress, false},
+		{"Lowercase", lowercaseAddress, false},
+		{"Uppercase", "0x" + strings.ToUpper(lowercaseAddress[2:]), false},
+		{"WithoutPrefix", lowercaseAddress[2:], false},
+		{"BadChecksum", "0x5b63Ca66637316A0D7f84Ebf60E50963c10059aD", true},
+		{"TooShort", "0x5b63ca66637316a0d7f84ebf60e50963c10059", true},
+		{"TooLong", lowercaseAddress + "00", true},
+		{"NotHex", "0xzz63ca66637316a0d7f84ebf60e50963c10059ad", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rawAddress, err := decodeAddress(tt.address)
+			if tt.expectErr {
+				require.Error(t, err, "address should be rejected")
+				return
+			}
+			require.NoError(t, err, "address should be accepted")
+			require.Equal(t, checksumAddress, encoding.EncodeHex(rawAddress), "address should be encoded with checksum")
+		})
+	}
+}
+
+func TestAddressCommands(t *testing.T) {
+	defer useTempAddressBook(t)()
+
+	tests := []struct {
+		name     string
+		command  func()
+		expected string
+	}{
+		{"ListEmpty", commandAddressList, "is empty"},
+		{"Add", func() { commandAddressAdd("exchange", lowercaseAddress) }, "Address " + checksumAddress + " added"},
+		{"AddSecond", func() { commandAddressAdd("alice", "0x"+strings.Repeat("00", ADDRESS_SIZE_BYTES)) }, "as 'alice'"},
+		{"Replace", func() { commandAddressAdd("exchange", checksumAddress) }, "Replacing existing address " + checksumAddress},
+		{"ListSorted", commandAddressList, "alice                0x0000000000000000000000000000000000000000\nexchange             " + checksumAddress + "\n"},
+		{"Remove", func() { commandAddressRemove("alice") }, "Address 'alice' removed"},
+		{"ListAfterRemove", commandAddressList, "exchange             " + checksumAddress + "\n"},
+		{"Subcommand", func() { commandAddress([]string{"validate", checksumAddress}) }, "valid with correct checksum"},
+		{"ValidateChecksum", func() { commandAddressValidate(checksumAddress) }, "valid with correct checksum"},
+		{"ValidateLowercase", func() { commandAddressValidate(lowercaseAddress) }, "not checksum encoded, checksum form is:\n" + checksumAddress},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Contains(t, captureStdout(t, tt.command), tt.expected)
+		})
+	}
+
+	book := readAddressBook()
+	require.Equal(t, jsoncodec.AddressBook{"exchange": checksumAddress}, book, "address book should be written with checksum addresses")
+	require.Equal(t, checksumAddress, encoding.EncodeHex(getAddressFromBook("exchange")), "book addresses should resolve for gamma:book-address")
+}
+
+func TestAddressFromPublicKey(t *testing.T) {
+	account, err := orbs.CreateAccount()
+	require.NoError(t, err, "account should be created")
+
+	output := captureStdout(t, func() { commandAddressFromPublicKey(encoding.EncodeHex(account.PublicKey)) })
+	require.Equal(t, account.Address+"\n", output, "address should match the one derived by the client sdk")
+}
+
+func TestGetAddressFromBook_Arguments(t *testing.T) {
+	defer useTempAddressBook(t)()
+	writeAddressBook(jsoncodec.AddressBook{"exchange": checksumAddress, "alice": lowercaseAddress})
+
+	args, err := jsoncodec.UnmarshalArgs([]*jsoncodec.Arg{
+		{Type: "gamma:book-address", Value: "exchange"},
+		{Type: "gamma:book-address", Value: "alice"},
+	}, getTestKeyFromFile, getAddressFromBook, nil)
+	require.NoError(t, err, "book addresses should be valid arguments")
+
+	rawAddress, err := decodeAddress(checksumAddress)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{rawAddress, rawAddress}, args, "book names should resolve to the raw address bytes")
+}
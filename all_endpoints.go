@@ -0,0 +1,213 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"encoding/json"
+	"github.com/orbs-network/gamma-cli/jsoncodec"
+	"github.com/orbs-network/orbs-client-sdk-go/orbs"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// fields compared between nodes, in the order they are reported
+// queries run on the latest block of each node, so healthy nodes a few blocks apart answer with different heights (reported apart, not divergent)
+// while a committed transaction must be in the same block on every node
+var queryResponseFields = []string{"Error", "RequestStatus", "ExecutionResult", "OutputArguments", "OutputEvents"}
+var txStatusResponseFields = []string{"Error", "RequestStatus", "ExecutionResult", "TransactionStatus", "BlockHeight", "OutputArguments", "OutputEvents"}
+
+var errorUrlPattern = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^\s"']+`)
+
+type endpointRequest struct {
+	send          func(client *orbs.OrbsClient) *jsoncodec.EndpointResponse
+	fields        []string
+	reportHeights bool // mark nodes at another BlockHeight without making them divergent
+}
+
+// sends the same request to every endpoint concurrently and prints the responses with the nodes that diverge, exits nonzero on divergence
+func commandAllEndpoints(client *gammaClient, request endpointRequest) {
+	report := requestAllEndpoints(client, request)
+
+	output, err := jsoncodec.MarshalEndpointsReport(report)
+	if err != nil {
+		die("Could not encode endpoints report to json.\n\n%s", err.Error())
+	}
+	log("%s\n", string(output))
+
+	if !report.Consistent {
+		os.Exit(1)
+	}
+	exit()
+}
+
+func requestAllEndpoints(client *gammaClient, request endpointRequest) *jsoncodec.EndpointsReport {
+	nodes := make([]*jsoncodec.EndpointResponse, len(client.endpoints))
+	var wg sync.WaitGroup
+	for i, endpoint := range client.endpoints {
+		wg.Add(1)
+		go func(i int, endpoint string) {
+			defer wg.Done()
+			nodes[i] = request.send(orbs.NewClient(endpoint, client.VirtualChainId, client.NetworkType))
+			nodes[i].Endpoint = endpoint
+		}(i, endpoint)
+	}
+	wg.Wait()
+
+	report := diffEndpointResponses(nodes, request.fields)
+	if request.reportHeights {
+		diffEndpointHeights(report)
+	}
+	return report
+}
+
+// every node is compared field by field with the value most nodes returned (the first node wins ties)
+func diffEndpointResponses(nodes []*jsoncodec.EndpointResponse, fields []string) *jsoncodec.EndpointsReport {
+	report := &jsoncodec.EndpointsReport{Consistent: true, Nodes: nodes}
+	for _, field := range fields {
+		values, majority := majorityEndpointResponseField(nodes, field)
+		for i, node := range nodes {
+			if values[i] != majority {
+				node.Divergent = append(node.Divergent, field)
+				report.Consistent = false
+			}
+		}
+	}
+	return report
+}
+
+// nodes that failed have no height and already diverge on Error
+func diffEndpointHeights(report *jsoncodec.EndpointsReport) {
+	var answered []*jsoncodec.EndpointResponse
+	for _, node := range report.Nodes {
+		if node.Error == "" {
+			answered = append(answered, node)
+		}
+	}
+	if len(answered) == 0 {
+		return
+	}
+
+	values, majority := majorityEndpointResponseField(answered, "BlockHeight")
+	for i, node := range answered {
+		if values[i] != majority {
+			node.HeightDiffers = true
+			report.HeightsDiffer = true
+		}
+	}
+}
+
+func majorityEndpointResponseField(nodes []*jsoncodec.EndpointResponse, field string) ([]string, string) {
+	values := make([]string, len(nodes))
+	counts := make(map[string]int)
+	for i, node := range nodes {
+		values[i] = endpointResponseField(node, field)
+		counts[values[i]]++
+	}
+
+	majority := values[0]
+	for _, value := range values {
+		if counts[value] > counts[majority] {
+			majority = value
+		}
+	}
+	return values, majority
+}
+
+func endpointResponseField(node *jsoncodec.EndpointResponse, field string) string {
+	switch field {
+	case "Error":
+		return errorWithoutEndpoint(node.Error, node.Endpoint)
+	case "RequestStatus":
+		return string(node.RequestStatus)
+	case "ExecutionResult":
+		return string(node.ExecutionResult)
+	case "TransactionStatus":
+		return string(node.TransactionStatus)
+	case "BlockHeight":
+		return node.BlockHeight
+	case "OutputArguments":
+		bytes, _ := json.Marshal(node.OutputArguments)
+		return string(bytes)
+	case "OutputEvents":
+		bytes, _ := json.Marshal(node.OutputEvents)
+		return string(bytes)
+	}
+	return ""
+}
+
+// errors name the node they come from (eg. Post "http://node1:8080/api/v1/run-query": dial tcp node1:8080: ...),
+// which alone should not make nodes failing the same way diverge
+func errorWithoutEndpoint(message string, endpoint string) string {
+	res := errorUrlPattern.ReplaceAllString(message, "<endpoint>")
+	if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+		res = strings.Replace(res, u.Host, "<endpoint>", -1)
+		res = strings.Replace(res, u.Hostname(), "<endpoint>", -1)
+	}
+	return res
+}
+
+func queryEndpointRequest(payload []byte) endpointRequest {
+	return endpointRequest{fields: queryResponseFields, reportHeights: true, send: func(client *orbs.OrbsClient) *jsoncodec.EndpointResponse {
+		response, err := client.SendQuery(payload)
+		if response == nil {
+			return endpointErrorResponse(err)
+		}
+
+		outputArgs, err := jsoncodec.MarshalArgs(response.OutputArguments, getArgsOptions())
+		if err != nil {
+			return endpointErrorResponse(err)
+		}
+		outputEvents, err := jsoncodec.MarshalEvents(response.OutputEvents, getArgsOptions())
+		if err != nil {
+			return endpointErrorResponse(err)
+		}
+		return &jsoncodec.EndpointResponse{
+			RequestStatus:   response.RequestStatus,
+			ExecutionResult: response.ExecutionResult,
+			BlockHeight:     strconv.FormatUint(response.BlockHeight, 10),
+			OutputArguments: outputArgs,
+			OutputEvents:    outputEvents,
+		}
+	}}
+}
+
+func txStatusEndpointRequest(txId string) endpointRequest {
+	return endpointRequest{fields: txStatusResponseFields, send: func(client *orbs.OrbsClient) *jsoncodec.EndpointResponse {
+		response, err := client.GetTransactionStatus(txId)
+		if response == nil {
+			return endpointErrorResponse(err)
+		}
+
+		outputArgs, err := jsoncodec.MarshalArgs(response.OutputArguments, getArgsOptions())
+		if err != nil {
+			return endpointErrorResponse(err)
+		}
+		outputEvents, err := jsoncodec.MarshalEvents(response.OutputEvents, getArgsOptions())
+		if err != nil {
+			return endpointErrorResponse(err)
+		}
+		return &jsoncodec.EndpointResponse{
+			RequestStatus:     response.RequestStatus,
+			ExecutionResult:   response.ExecutionResult,
+			TransactionStatus: response.TransactionStatus,
+			BlockHeight:       strconv.FormatUint(response.BlockHeight, 10),
+			OutputArguments:   outputArgs,
+			OutputEvents:      outputEvents,
+		}
+	}}
+}
+
+func endpointErrorResponse(err error) *jsoncodec.EndpointResponse {
+	if err == nil {
+		return &jsoncodec.EndpointResponse{Error: "empty response"}
+	}
+	return &jsoncodec.EndpointResponse{Error: err.Error()}
+}
@@ -0,0 +1,86 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"github.com/orbs-network/gamma-cli/jsoncodec"
+	"github.com/orbs-network/orbs-client-sdk-go/codec"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDiffEndpointResponses(t *testing.T) {
+	response := func(blockHeight string, value string) *jsoncodec.EndpointResponse {
+		return &jsoncodec.EndpointResponse{
+			RequestStatus:   codec.REQUEST_STATUS_COMPLETED,
+			ExecutionResult: codec.EXECUTION_RESULT_SUCCESS,
+			BlockHeight:     blockHeight,
+			OutputArguments: []*jsoncodec.Arg{{Type: "uint64", Value: value}},
+		}
+	}
+
+	report := diffEndpointResponses([]*jsoncodec.EndpointResponse{response("10", "5"), response("10", "5"), response("10", "5")}, txStatusResponseFields)
+	require.True(t, report.Consistent, "identical responses should be consistent")
+
+	report = diffEndpointResponses([]*jsoncodec.EndpointResponse{
+		response("10", "5"),
+		response("9", "4"),
+		response("10", "5"),
+		{Error: "cannot connect to server"},
+	}, txStatusResponseFields)
+	require.False(t, report.Consistent, "divergent responses should be flagged")
+	require.Empty(t, report.Nodes[0].Divergent, "majority node should not diverge")
+	require.Equal(t, []string{"BlockHeight", "OutputArguments"}, report.Nodes[1].Divergent, "node behind should diverge on height and outputs")
+	require.Empty(t, report.Nodes[2].Divergent, "majority node should not diverge")
+	require.Equal(t, []string{"Error", "RequestStatus", "ExecutionResult", "BlockHeight", "OutputArguments"}, report.Nodes[3].Divergent, "unreachable node should diverge on everything it did not return")
+}
+
+func TestDiffEndpointResponses_QueryReportsBlockHeightApart(t *testing.T) {
+	response := func(blockHeight string) *jsoncodec.EndpointResponse {
+		return &jsoncodec.EndpointResponse{
+			RequestStatus:   codec.REQUEST_STATUS_COMPLETED,
+			ExecutionResult: codec.EXECUTION_RESULT_SUCCESS,
+			BlockHeight:     blockHeight,
+			OutputArguments: []*jsoncodec.Arg{{Type: "uint64", Value: "5"}},
+		}
+	}
+
+	report := diffEndpointResponses([]*jsoncodec.EndpointResponse{response("10"), response("8"), response("10"), {Error: "cannot connect to server"}}, queryResponseFields)
+	diffEndpointHeights(report)
+	require.False(t, report.Consistent, "unreachable node should still diverge")
+	require.True(t, report.HeightsDiffer, "different heights should be reported")
+	require.Empty(t, report.Nodes[1].Divergent, "nodes answering a query a few blocks apart should not diverge")
+	require.True(t, report.Nodes[1].HeightDiffers, "node behind should be marked")
+	require.False(t, report.Nodes[0].HeightDiffers || report.Nodes[2].HeightDiffers || report.Nodes[3].HeightDiffers, "majority and failed nodes should not be marked")
+
+	report = diffEndpointResponses([]*jsoncodec.EndpointResponse{response("10"), response("8"), response("10")}, txStatusResponseFields)
+	require.Equal(t, []string{"BlockHeight"}, report.Nodes[1].Divergent, "a transaction committed in another block should diverge")
+}
+
+func TestDiffEndpointResponses_ErrorsFromDifferentNodes(t *testing.T) {
+	report := diffEndpointResponses([]*jsoncodec.EndpointResponse{
+		{Endpoint: "http://node1:8080", Error: `Post "http://node1:8080/api/v1/run-query": dial tcp node1:8080: connect: connection refused`},
+		{Endpoint: "http://node2:8080", Error: `Post "http://node2:8080/api/v1/run-query": dial tcp node2:8080: connect: connection refused`},
+		{Endpoint: "http://node3:8080", Error: `Post "http://node3:8080/api/v1/run-query": dial tcp: lookup node3: no such host`},
+	}, queryResponseFields)
+	require.Empty(t, report.Nodes[0].Divergent, "the same error from another node should not diverge")
+	require.Empty(t, report.Nodes[1].Divergent, "the same error from another node should not diverge")
+	require.Equal(t, []string{"Error"}, report.Nodes[2].Divergent, "another error should diverge")
+}
+
+func TestDiffEndpointResponses_OutputEvents(t *testing.T) {
+	response := func(eventName string) *jsoncodec.EndpointResponse {
+		return &jsoncodec.EndpointResponse{
+			RequestStatus:   codec.REQUEST_STATUS_COMPLETED,
+			ExecutionResult: codec.EXECUTION_RESULT_SUCCESS,
+			OutputEvents:    []*jsoncodec.Event{{ContractName: "MyToken", EventName: eventName}},
+		}
+	}
+
+	report := diffEndpointResponses([]*jsoncodec.EndpointResponse{response("Transfer"), response("Approval"), response("Transfer")}, txStatusResponseFields)
+	require.Equal(t, []string{"OutputEvents"}, report.Nodes[1].Divergent, "different events should diverge")
+}
@@ -0,0 +1,367 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var goModulePattern = regexp.MustCompile(`(?m)^module\s+"?([^"\s]+)"?`)
+
+type contractSourceFile struct {
+	filename string
+	source   []byte
+}
+
+type contractBundler struct {
+	fset         *token.FileSet
+	moduleRoot   string
+	modulePath   string
+	visitedDirs  map[string]bool
+	pendingDirs  []string
+	declarations map[string]string // top level name -> file declaring it
+	files        []*contractSourceFile
+	warnings     []string // imports that look local but are not bundled
+}
+
+type sourceEdit struct {
+	start int
+	end   int
+	text  string
+}
+
+// returns the deployed code of the contract, go contracts are bundled together with the local packages they import
+func getContractCode(codeFile string, filenames []string, processorType uint32) [][]byte {
+	if processorType != PROCESSOR_TYPE_NATIVE {
+		code, err := _getSource(codeFile)
+		if err != nil {
+			die("Could not find path\n\n%s", err.Error())
+		}
+		return code
+	}
+
+	files, warnings, err := bundleContractSources(filenames)
+	if err != nil {
+		die("Could not bundle contract sources of '%s'.\n\n%s", codeFile, err.Error())
+	}
+
+	// stderr, stdout is reserved for the json response of deploy
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "WARNING: %s\n", warning)
+	}
+	var code [][]byte
+	totalSize := 0
+	for _, file := range files {
+		code = append(code, file.source)
+		totalSize += len(file.source)
+	}
+	if *flagQuiet {
+		return code
+	}
+
+	fmt.Fprintf(os.Stderr, "Contract source files:\n")
+	for _, file := range files {
+		fmt.Fprintf(os.Stderr, "  %s (%d bytes)\n", file.filename, len(file.source))
+	}
+	fmt.Fprintf(os.Stderr, "Total: %d files, %d bytes\n\n", len(files), totalSize)
+	return code
+}
+
+// the contract files are followed by the files of every package inside the go module they import (directly or not),
+// rewritten to package main with the package qualifiers removed; _test.go files and files the node would not build are excluded
+// relative imports and the imports of contracts outside a go module are not bundled, they are returned as warnings
+func bundleContractSources(filenames []string) ([]*contractSourceFile, []string, error) {
+	moduleRoot, modulePath, err := findGoModule(filepath.Dir(filenames[0]))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	b := &contractBundler{
+		fset:         token.NewFileSet(),
+		moduleRoot:   moduleRoot,
+		modulePath:   modulePath,
+		visitedDirs:  make(map[string]bool),
+		declarations: make(map[string]string),
+	}
+
+	for _, filename := range filenames {
+		if err := b.addFile(filename, false); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(b.files) == 0 {
+		return nil, nil, errors.New("all contract source files are excluded by build constraints")
+	}
+
+	for len(b.pendingDirs) > 0 {
+		dir := b.pendingDirs[0]
+		b.pendingDirs = b.pendingDirs[1:]
+
+		packageFiles, err := listPackageSourceFiles(dir)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, filename := range packageFiles {
+			if err := b.addFile(filename, true); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	return b.files, b.warnings, nil
+}
+
+func (b *contractBundler) addFile(filename string, isDependency bool) error {
+	if match, err := matchesContractBuild(filename); err != nil || !match {
+		return err
+	}
+
+	source, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	file, err := parser.ParseFile(b.fset, filename, source, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+
+	if err := b.addDeclarations(filename, file); err != nil {
+		return err
+	}
+
+	var edits []*sourceEdit
+	if isDependency {
+		edits = append(edits, b.edit(file.Name.Pos(), file.Name.End(), CONTRACT_PACKAGE_NAME))
+	}
+
+	localNames := make(map[string]bool)
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			importSpec := spec.(*ast.ImportSpec)
+			importPath, _ := strconv.Unquote(importSpec.Path.Value)
+			dir, isLocal := b.localPackageDir(importPath)
+			if !isLocal {
+				if warning := b.unbundledImportWarning(importPath); warning != "" {
+					b.warnings = append(b.warnings, fmt.Sprintf("%s: %s", b.fset.Position(importSpec.Pos()), warning))
+				}
+				continue
+			}
+
+			name, err := packageNameOfDir(dir)
+			if err != nil {
+				return errors.Wrapf(err, "%s: could not read local package '%s'", b.fset.Position(importSpec.Pos()), importPath)
+			}
+			if importSpec.Name != nil {
+				name = importSpec.Name.Name
+			}
+			localNames[name] = true
+
+			if !genDecl.Lparen.IsValid() {
+				edits = append(edits, b.edit(genDecl.Pos(), genDecl.End(), ""))
+			} else {
+				edits = append(edits, b.edit(importSpec.Pos(), importSpec.End(), ""))
+			}
+
+			if !b.visitedDirs[dir] {
+				b.visitedDirs[dir] = true
+				b.pendingDirs = append(b.pendingDirs, dir)
+			}
+		}
+	}
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		selector, ok := node.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		// package names are never resolved by the parser, a resolved ident is a local variable shadowing the package
+		if ident, ok := selector.X.(*ast.Ident); ok && ident.Obj == nil && localNames[ident.Name] {
+			edits = append(edits, b.edit(ident.Pos(), selector.Sel.Pos(), ""))
+		}
+		return true
+	})
+
+	if len(edits) > 0 {
+		source, err = applySourceEdits(source, edits)
+		if err != nil {
+			return errors.Wrapf(err, "could not rewrite '%s'", filename)
+		}
+	}
+
+	b.files = append(b.files, &contractSourceFile{filename: filename, source: source})
+	return nil
+}
+
+// all bundled files end up in package main, so their top level names must not collide
+func (b *contractBundler) addDeclarations(filename string, file *ast.File) error {
+	var names []*ast.Ident
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv == nil && decl.Name.Name != "init" {
+				names = append(names, decl.Name)
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					names = append(names, spec.Name)
+				case *ast.ValueSpec:
+					names = append(names, spec.Names...)
+				}
+			}
+		}
+	}
+
+	for _, name := range names {
+		if name.Name == "_" {
+			continue
+		}
+		if other, found := b.declarations[name.Name]; found {
+			return errors.Errorf("%s: '%s' is also declared in '%s', bundled packages share a single namespace", b.fset.Position(name.Pos()), name.Name, other)
+		}
+		b.declarations[name.Name] = filename
+	}
+	return nil
+}
+
+func (b *contractBundler) localPackageDir(importPath string) (string, bool) {
+	if b.modulePath == "" {
+		return "", false
+	}
+	if importPath != b.modulePath && !strings.HasPrefix(importPath, b.modulePath+"/") {
+		return "", false
+	}
+	return filepath.Join(b.moduleRoot, filepath.FromSlash(strings.TrimPrefix(importPath, b.modulePath))), true
+}
+
+// imports the node resolves on its own are the standard library and the contract sdk
+func (b *contractBundler) unbundledImportWarning(importPath string) string {
+	if build.IsLocalImport(importPath) {
+		return fmt.Sprintf("relative import '%s' is not bundled, put the contract in a go module and import the package by its module path", importPath)
+	}
+	if b.modulePath != "" || importPath == CONTRACT_SDK_MODULE || strings.HasPrefix(importPath, CONTRACT_SDK_MODULE+"/") {
+		return ""
+	}
+	if pkg, err := build.Default.Import(importPath, "", build.FindOnly); err == nil && pkg.Goroot {
+		return ""
+	}
+	return fmt.Sprintf("import '%s' is not bundled since the contract is not in a go module, add a go.mod to bundle the packages of the module", importPath)
+}
+
+func (b *contractBundler) edit(start token.Pos, end token.Pos, text string) *sourceEdit {
+	return &sourceEdit{
+		start: b.fset.Position(start).Offset,
+		end:   b.fset.Position(end).Offset,
+		text:  text,
+	}
+}
+
+func applySourceEdits(source []byte, edits []*sourceEdit) ([]byte, error) {
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].start > edits[j].start
+	})
+
+	res := string(source)
+	for _, edit := range edits {
+		res = res[:edit.start] + edit.text + res[edit.end:]
+	}
+	return format.Source([]byte(res))
+}
+
+// build constraints and _GOOS/_GOARCH file name suffixes are evaluated for the node, which builds contracts on linux/amd64
+func matchesContractBuild(filename string) (bool, error) {
+	ctxt := build.Default
+	ctxt.GOOS = "linux"
+	ctxt.GOARCH = "amd64"
+	ctxt.BuildTags = nil
+	return ctxt.MatchFile(filepath.Dir(filename), filepath.Base(filename))
+}
+
+func listPackageSourceFiles(dir string) ([]string, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []string
+	for _, f := range files {
+		if !f.IsDir() && strings.HasSuffix(f.Name(), ".go") && !strings.HasSuffix(f.Name(), "_test.go") {
+			res = append(res, path.Join(dir, f.Name()))
+		}
+	}
+	return res, nil
+}
+
+func packageNameOfDir(dir string) (string, error) {
+	filenames, err := listPackageSourceFiles(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for _, filename := range filenames {
+		if match, err := matchesContractBuild(filename); err != nil || !match {
+			if err != nil {
+				return "", err
+			}
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), filename, nil, parser.PackageClauseOnly)
+		if err != nil {
+			return "", err
+		}
+		return file.Name.Name, nil
+	}
+	return "", errors.Errorf("no go source files found in '%s'", dir)
+}
+
+// returns the root dir and module path of the go.mod enclosing dir, or empty strings if there is none
+func findGoModule(dir string) (string, string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", "", err
+	}
+
+	for {
+		if doesFileExist(filepath.Join(dir, "go.mod")) {
+			bytes, err := ioutil.ReadFile(filepath.Join(dir, "go.mod"))
+			if err != nil {
+				return "", "", err
+			}
+			m := goModulePattern.FindSubmatch(bytes)
+			if m == nil {
+				return "", "", errors.Errorf("no module directive found in '%s'", filepath.Join(dir, "go.mod"))
+			}
+			return dir, string(m[1]), nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", nil
+		}
+		dir = parent
+	}
+}
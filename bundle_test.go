@@ -0,0 +1,148 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func writeTempModule(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "gamma-cli-module")
+	require.NoError(t, err, "temp dir should be created")
+	for filename, source := range files {
+		require.NoError(t, os.MkdirAll(path.Dir(path.Join(dir, filename)), 0755), "package dir should be created")
+		require.NoError(t, ioutil.WriteFile(path.Join(dir, filename), []byte(source), 0644), "source file should be written")
+	}
+	return dir
+}
+
+func TestBundleContractSources(t *testing.T) {
+	contract := `package main
+
+import (
+	"example.com/tokens/lib/math"
+	"github.com/orbs-network/orbs-contract-sdk/go/sdk/v1"
+)
+
+var PUBLIC = sdk.Export(add)
+var SYSTEM = sdk.Export(_init)
+
+func _init() {
+}
+
+func add(a uint64, b uint64) uint64 {
+	return math.SafeAdd(a, b)
+}
+`
+	dir := writeTempModule(t, map[string]string{
+		"go.mod":                    "module example.com/tokens\n",
+		"contract/contract.go":      contract,
+		"contract/contract_test.go": "package main\n",
+		"lib/math/add.go":           "package math\n\nimport \"example.com/tokens/lib/overflow\"\n\nfunc SafeAdd(a uint64, b uint64) uint64 {\n\toverflow.Check(a, b)\n\treturn a + b\n}\n",
+		"lib/math/add_test.go":      "package math\n",
+		"lib/math/debug.go":         "// +build debug\n\npackage math\n",
+		"lib/math/unix.go":          "//go:build !windows\n\npackage math\n\nconst Platform = \"unix\"\n",
+		"lib/math/add_windows.go":   "package math\n\nconst Platform = \"windows\"\n",
+		"lib/overflow/overflow.go":  "package overflow\n\nfunc Check(a uint64, b uint64) {\n\tif a+b < a {\n\t\tpanic(\"overflow\")\n\t}\n}\n",
+	})
+	defer os.RemoveAll(dir)
+
+	filenames, err := _getSourceFilenames(path.Join(dir, "contract"))
+	require.NoError(t, err, "source filenames should be listed")
+
+	files, warnings, err := bundleContractSources(filenames)
+	require.NoError(t, err, "bundle should succeed")
+	require.Empty(t, warnings, "all local imports should be bundled")
+
+	var bundled []string
+	for _, file := range files {
+		bundled = append(bundled, file.filename[len(dir)+1:])
+	}
+	require.Equal(t, []string{"contract/contract.go", "lib/math/add.go", "lib/math/unix.go", "lib/overflow/overflow.go"}, bundled, "bundle should follow local imports and exclude test files and files the node would not build")
+
+	require.NotContains(t, string(files[0].source), "example.com/tokens", "local imports should be removed")
+	require.Contains(t, string(files[0].source), "return SafeAdd(a, b)", "package qualifiers should be removed")
+	require.Contains(t, string(files[0].source), "\"github.com/orbs-network/orbs-contract-sdk/go/sdk/v1\"", "external imports should be kept")
+	require.Contains(t, string(files[1].source), "package main", "dependencies should be rewritten to package main")
+	require.Contains(t, string(files[1].source), "\tCheck(a, b)", "transitive package qualifiers should be removed")
+
+	result := checkContractSources([]string{"contract.go", "add.go", "unix.go", "overflow.go"}, [][]byte{files[0].source, files[1].source, files[2].source, files[3].source})
+	require.Empty(t, result.errors, "bundled sources should form a valid contract")
+}
+
+func TestBundleContractSources_Unchanged(t *testing.T) {
+	contract := "package main\n\nimport \"fmt\"\n\nfunc hello() string {\n\treturn fmt.Sprint(\"hello\")\n}\n"
+	dir := writeTempModule(t, map[string]string{
+		"go.mod":      "module example.com/tokens\n",
+		"contract.go": contract,
+	})
+	defer os.RemoveAll(dir)
+
+	files, _, err := bundleContractSources([]string{path.Join(dir, "contract.go")})
+	require.NoError(t, err, "bundle should succeed")
+	require.Len(t, files, 1, "only the contract file should be bundled")
+	require.Equal(t, contract, string(files[0].source), "contract without local imports should be deployed as is")
+}
+
+func TestBundleContractSources_DeclarationCollision(t *testing.T) {
+	dir := writeTempModule(t, map[string]string{
+		"go.mod":           "module example.com/tokens\n",
+		"contract/main.go": "package main\n\nimport \"example.com/tokens/lib\"\n\nfunc add() uint64 {\n\treturn lib.Value\n}\n",
+		"lib/lib.go":       "package lib\n\nvar Value = uint64(1)\n\nfunc add() {\n}\n",
+	})
+	defer os.RemoveAll(dir)
+
+	_, _, err := bundleContractSources([]string{path.Join(dir, "contract/main.go")})
+	require.Error(t, err, "colliding top level names should fail the bundle")
+	require.Contains(t, err.Error(), "'add' is also declared", "error should name the colliding declaration")
+}
+
+func TestBundleContractSources_FixtureTree(t *testing.T) {
+	filenames, err := _getSourceFilenames("./test/_bundle/token")
+	require.NoError(t, err, "source filenames should be listed")
+
+	files, warnings, err := bundleContractSources(filenames)
+	require.NoError(t, err, "bundle should succeed")
+	require.Empty(t, warnings, "all local imports should be bundled")
+
+	var bundled, bundledFilenames []string
+	var sources [][]byte
+	for _, file := range files {
+		bundled = append(bundled, path.Base(path.Dir(file.filename))+"/"+path.Base(file.filename))
+		bundledFilenames = append(bundledFilenames, file.filename)
+		sources = append(sources, file.source)
+	}
+	require.Equal(t, []string{"token/supply.go", "token/token.go", "math/add.go", "math/platform.go", "math/sub.go", "format/format.go"}, bundled, "bundle should include every file of the contract and its packages except test files and files excluded by build constraints")
+
+	result := checkContractSources(bundledFilenames, sources)
+	require.Empty(t, result.errors, "bundled sources should form a valid contract")
+}
+
+func TestBundleContractSources_FixtureTreeCollision(t *testing.T) {
+	_, _, err := bundleContractSources([]string{"./test/_bundle/collision/main.go"})
+	require.Error(t, err, "names declared by two imported packages should fail the bundle")
+	require.Regexp(t, `'SafeAdd' is also declared in '.*lib/(dup/dup|math/add)\.go'`, err.Error(), "error should name the colliding declaration and the file declaring it")
+}
+
+func TestBundleContractSources_NotBundledWarnings(t *testing.T) {
+	dir := writeTempModule(t, map[string]string{
+		"contract/main.go": "package main\n\nimport (\n\t\"../lib\"\n\t\"example.com/other/lib\"\n\t\"github.com/orbs-network/orbs-contract-sdk/go/sdk/v1\"\n\t\"strconv\"\n)\n",
+		"lib/lib.go":       "package lib\n",
+	})
+	defer os.RemoveAll(dir)
+
+	files, warnings, err := bundleContractSources([]string{path.Join(dir, "contract/main.go")})
+	require.NoError(t, err, "bundle should succeed")
+	require.Len(t, files, 1, "only the contract file should be bundled outside a go module")
+	require.Len(t, warnings, 2, "the relative import and the non standard import should be reported")
+	require.Contains(t, warnings[0], "main.go:4:2: relative import '../lib' is not bundled", "warning should point to the relative import")
+	require.Contains(t, warnings[1], "main.go:5:2: import 'example.com/other/lib' is not bundled since the contract is not in a go module", "warning should point to the import")
+}
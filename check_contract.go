@@ -0,0 +1,281 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const CONTRACT_PACKAGE_NAME = "main"
+
+var unresolvedImportPattern = regexp.MustCompile(`could not import (\S+)`)
+var majorVersionPattern = regexp.MustCompile(`^v[0-9]+$`)
+
+const CONTRACT_SDK_MODULE = "github.com/orbs-network/orbs-contract-sdk"
+
+type contractCheckResult struct {
+	errors   []string
+	warnings []string
+}
+
+func commandCheckContract(requiredOptions []string) {
+	codeFile := requiredOptions[0]
+
+	result := checkContractPath(codeFile)
+	printContractCheckResult(result)
+	if len(result.errors) > 0 {
+		die("Contract '%s' has %d errors.", codeFile, len(result.errors))
+	}
+
+	log("Contract '%s' passed all checks.", codeFile)
+}
+
+// called by deploy -precheck before the deploy transaction is signed
+func precheckContract(codeFile string, processorType uint32) {
+	if processorType != PROCESSOR_TYPE_NATIVE {
+		log("WARNING: contract precheck is only supported for go contracts, skipping.")
+		return
+	}
+
+	result := checkContractPath(codeFile)
+	printContractCheckResult(result)
+	if len(result.errors) > 0 {
+		die("Contract precheck of '%s' failed with %d errors, deploy aborted.", codeFile, len(result.errors))
+	}
+}
+
+func printContractCheckResult(result *contractCheckResult) {
+	for _, warning := range result.warnings {
+		fmt.Fprintf(os.Stderr, "WARNING: %s\n", warning)
+	}
+	for _, err := range result.errors {
+		log("%s", err)
+	}
+}
+
+func checkContractPath(codeFile string) *contractCheckResult {
+	filenames, err := _getSourceFilenames(codeFile)
+	if err != nil {
+		die("Could not find path\n\n%s", err.Error())
+	}
+	if len(filenames) == 0 {
+		die("No go source files found in '%s'.", codeFile)
+	}
+	if getProcessorTypeFromFilename(filenames[0]) != PROCESSOR_TYPE_NATIVE {
+		die("Contract checks are only supported for go contracts.")
+	}
+
+	var sources [][]byte
+	for _, filename := range filenames {
+		source, err := ioutil.ReadFile(filename)
+		if err != nil {
+			die("Could not read contract source '%s'.\n\n%s", filename, err.Error())
+		}
+		sources = append(sources, source)
+	}
+
+	return checkContractSources(filenames, sources)
+}
+
+// parses and type checks the contract and enforces the package main and PUBLIC/SYSTEM export conventions
+func checkContractSources(filenames []string, sources [][]byte) *contractCheckResult {
+	result := &contractCheckResult{}
+	fset := token.NewFileSet()
+
+	var files []*ast.File
+	for i, source := range sources {
+		file, err := parser.ParseFile(fset, filenames[i], source, 0)
+		if err != nil {
+			if list, ok := err.(scanner.ErrorList); ok {
+				for _, e := range list {
+					result.errors = append(result.errors, e.Error())
+				}
+			} else {
+				result.errors = append(result.errors, err.Error())
+			}
+			continue
+		}
+		files = append(files, file)
+	}
+	if len(result.errors) > 0 {
+		return result
+	}
+
+	for _, file := range files {
+		if file.Name.Name != CONTRACT_PACKAGE_NAME {
+			result.errors = append(result.errors, fmt.Sprintf("%s: contract package should be '%s' but is '%s'", fset.Position(file.Name.Pos()), CONTRACT_PACKAGE_NAME, file.Name.Name))
+		}
+	}
+
+	for _, visibility := range []string{"PUBLIC", "SYSTEM"} {
+		if !isExportDeclared(files, visibility) {
+			result.errors = append(result.errors, fmt.Sprintf("%s: contract should declare 'var %s = sdk.Export(...)'", fset.Position(files[0].Name.Pos()), visibility))
+		}
+	}
+
+	if _, err := contractAbiFromFiles(fset, "", files); err != nil {
+		result.errors = append(result.errors, err.Error())
+	}
+
+	typeErrors, warnings := typeCheckContract(fset, files)
+	result.errors = append(result.errors, typeErrors...)
+	result.warnings = append(result.warnings, warnings...)
+
+	return result
+}
+
+func isExportDeclared(files []*ast.File, visibility string) bool {
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				valueSpec := spec.(*ast.ValueSpec)
+				for j, name := range valueSpec.Names {
+					if name.Name == visibility && j < len(valueSpec.Values) && isSdkExportCall(valueSpec.Values[j]) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+func isSdkExportCall(expr ast.Expr) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	return ok && selector.Sel.Name == SDK_EXPORT_FUNCTION
+}
+
+// imports are resolved from the directory of the contract sources, so from the go.mod of the contract's own module
+// an unresolved import is an error, except for the sdk which single file contracts outside a module usually cannot resolve,
+// the contract is then type checked without it (uses of the sdk are not checked) and a warning is returned
+func typeCheckContract(fset *token.FileSet, files []*ast.File) ([]string, []string) {
+	ctx := build.Default
+	ctx.CgoEnabled = false
+	if dir, err := filepath.Abs(filepath.Dir(fset.Position(files[0].Pos()).Filename)); err == nil {
+		ctx.Dir = dir // the go command resolving module imports runs in Dir, the working directory if empty
+	}
+
+	nameContractSdkImports(files)
+
+	var typeErrors, importErrors, warnings []string
+	config := &types.Config{
+		Importer: newSourceImporter(&ctx, fset),
+		Error: func(err error) {
+			m := unresolvedImportPattern.FindStringSubmatch(err.Error())
+			switch {
+			case m == nil:
+				typeErrors = append(typeErrors, err.Error())
+			case importModule(m[1]) == CONTRACT_SDK_MODULE:
+				warnings = append(warnings, fmt.Sprintf("%s: could not resolve import '%s', uses of the sdk were not type checked (add module %s to a go.mod of the contract to check them)", fset.Position(err.(types.Error).Pos), m[1], CONTRACT_SDK_MODULE))
+			default:
+				importErrors = append(importErrors, fmt.Sprintf("%s: could not resolve import '%s', add module %s to the go.mod of the contract (eg. go get %s)", fset.Position(err.(types.Error).Pos), m[1], importModule(m[1]), importModule(m[1])))
+			}
+		},
+	}
+
+	config.Check(CONTRACT_PACKAGE_NAME, fset, files, nil)
+	if len(importErrors) > 0 {
+		return importErrors, warnings
+	}
+	return typeErrors, warnings
+}
+
+// go/types names an unresolved import after the last element of its path, which is 'v1' for the sdk instead of 'sdk'
+// naming the sdk imports explicitly keeps an unresolved sdk from turning every use into an undefined name error
+func nameContractSdkImports(files []*ast.File) {
+	for _, file := range files {
+		for _, spec := range file.Imports {
+			path := strings.Trim(spec.Path.Value, "`\"")
+			if spec.Name != nil || importModule(path) != CONTRACT_SDK_MODULE {
+				continue
+			}
+			parts := strings.Split(path, "/")
+			name := parts[len(parts)-1]
+			if majorVersionPattern.MatchString(name) && len(parts) > 1 {
+				name = parts[len(parts)-2]
+			}
+			spec.Name = &ast.Ident{NamePos: spec.Path.Pos(), Name: name}
+		}
+	}
+}
+
+// type checks imported packages from source, located with the given build context instead of build.Default
+type sourceImporter struct {
+	ctx      *build.Context
+	fset     *token.FileSet
+	packages map[string]*types.Package // nil while the package is being imported
+}
+
+func newSourceImporter(ctx *build.Context, fset *token.FileSet) *sourceImporter {
+	return &sourceImporter{ctx: ctx, fset: fset, packages: make(map[string]*types.Package)}
+}
+
+func (imp *sourceImporter) Import(path string) (*types.Package, error) {
+	return imp.ImportFrom(path, imp.ctx.Dir, 0)
+}
+
+func (imp *sourceImporter) ImportFrom(path string, dir string, mode types.ImportMode) (*types.Package, error) {
+	if path == "unsafe" {
+		return types.Unsafe, nil
+	}
+	bp, err := imp.ctx.Import(path, dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	if pkg, found := imp.packages[bp.ImportPath]; found {
+		if pkg == nil {
+			return nil, errors.Errorf("import cycle through package %s", bp.ImportPath)
+		}
+		return pkg, nil
+	}
+
+	imp.packages[bp.ImportPath] = nil
+	var files []*ast.File
+	for _, filename := range bp.GoFiles {
+		file, err := parser.ParseFile(imp.fset, filepath.Join(bp.Dir, filename), nil, 0)
+		if err != nil {
+			delete(imp.packages, bp.ImportPath)
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	// errors inside dependencies are not the contract's, only their exported declarations are needed
+	config := &types.Config{Importer: imp, IgnoreFuncBodies: true, FakeImportC: true, Error: func(err error) {}}
+	pkg, _ := config.Check(bp.ImportPath, imp.fset, files, nil)
+	imp.packages[bp.ImportPath] = pkg
+	return pkg, nil
+}
+
+// the module of an import path, assuming the usual host/owner/repo layout
+func importModule(importPath string) string {
+	parts := strings.Split(importPath, "/")
+	if len(parts) > 3 && strings.Contains(parts[0], ".") {
+		return strings.Join(parts[:3], "/")
+	}
+	return importPath
+}
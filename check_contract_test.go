@@ -0,0 +1,108 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckContractSources(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected []string
+	}{
+		{
+			name:     "SyntaxError",
+			source:   "package main\nfunc add( {\n}\n",
+			expected: []string{"contract.go:2:11:"},
+		},
+		{
+			name:     "WrongPackageAndMissingExports",
+			source:   "package token\n",
+			expected: []string{"contract.go:1:9: contract package should be 'main' but is 'token'", "var PUBLIC = sdk.Export(...)", "var SYSTEM = sdk.Export(...)"},
+		},
+		{
+			name:     "UndeclaredExport",
+			source:   "package main\nimport \"github.com/orbs-network/orbs-contract-sdk/go/sdk/v1\"\nvar PUBLIC = sdk.Export(get)\nvar SYSTEM = sdk.Export(_init)\nfunc _init() {}\n",
+			expected: []string{"contract.go:3:25: function 'get' exported in PUBLIC is not declared"},
+		},
+		{
+			name:     "TypeError",
+			source:   "package main\nimport \"github.com/orbs-network/orbs-contract-sdk/go/sdk/v1\"\nvar PUBLIC = sdk.Export(get)\nvar SYSTEM = sdk.Export(_init)\nfunc _init() {}\nfunc get() uint64 {\n\treturn \"zero\"\n}\n",
+			expected: []string{"contract.go:7:9:"},
+		},
+		{
+			name:   "Valid",
+			source: "package main\nimport \"github.com/orbs-network/orbs-contract-sdk/go/sdk/v1\"\nvar PUBLIC = sdk.Export(get)\nvar SYSTEM = sdk.Export(_init)\nfunc _init() {}\nfunc get() uint64 {\n\treturn 0\n}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := checkContractSources([]string{"contract.go"}, [][]byte{[]byte(tt.source)})
+			require.Equal(t, len(tt.expected) == 0, len(result.errors) == 0, "unexpected errors %v", result.errors)
+			for _, expected := range tt.expected {
+				require.Contains(t, strings.Join(result.errors, "\n"), expected, "errors should describe the problem with its position")
+			}
+		})
+	}
+}
+
+func TestCheckContractPath_OutsideGammaCliModule(t *testing.T) {
+	goSum, err := ioutil.ReadFile("go.sum")
+	require.NoError(t, err)
+	header := "package main\nimport \"github.com/orbs-network/orbs-contract-sdk/go/sdk/v1\"\nvar PUBLIC = sdk.Export(get)\nvar SYSTEM = sdk.Export(_init)\nfunc _init() {}\n"
+	valid := header + "func get() uint64 {\n\treturn 0\n}\n"
+	typeError := header + "func get() uint64 {\n\treturn \"zero\"\n}\n"
+	otherImport := "package main\nimport (\n\t\"example.com/missing/lib\"\n\t\"github.com/orbs-network/orbs-contract-sdk/go/sdk/v1\"\n)\nvar PUBLIC = sdk.Export(get)\nvar SYSTEM = sdk.Export(_init)\nfunc _init() {}\nfunc get() uint64 {\n\treturn lib.Value\n}\n"
+	sdkWarning := "could not resolve import 'github.com/orbs-network/orbs-contract-sdk/go/sdk/v1', uses of the sdk were not type checked"
+	withSdk := "module example.com/token\n\ngo 1.13\n\nrequire github.com/orbs-network/orbs-contract-sdk v1.4.0\n"
+
+	tests := []struct {
+		name            string
+		source          string
+		goMod           string
+		expectedError   string
+		expectedWarning string
+	}{
+		{"NoModule", valid, "", "", sdkWarning},
+		{"NoModuleTypeError", typeError, "", "contract.go:7:9:", sdkWarning},
+		{"ModuleWithoutSdk", valid, "module example.com/token\n\ngo 1.13\n", "", sdkWarning},
+		{"ModuleWithSdk", valid, withSdk, "", ""},
+		{"ModuleWithSdkTypeError", typeError, withSdk, "contract.go:7:9:", ""},
+		{"UnresolvedOtherImport", otherImport, withSdk, "could not resolve import 'example.com/missing/lib', add module example.com/missing/lib to the go.mod of the contract", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "gamma-cli-contract")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "contract.go"), []byte(tt.source), 0644))
+			if tt.goMod != "" {
+				require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte(tt.goMod), 0644))
+				require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.sum"), goSum, 0644))
+			}
+
+			result := checkContractPath(filepath.Join(dir, "contract.go"))
+			if tt.expectedError == "" {
+				require.Empty(t, result.errors, "contract should pass the check")
+			} else {
+				require.Contains(t, strings.Join(result.errors, "\n"), tt.expectedError)
+			}
+			if tt.expectedWarning == "" {
+				require.Empty(t, result.warnings)
+			} else {
+				require.Contains(t, strings.Join(result.warnings, "\n"), tt.expectedWarning)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/orbs-network/orbs-client-sdk-go/codec"
+	"github.com/orbs-network/orbs-client-sdk-go/orbs"
+	"github.com/pkg/errors"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const ENDPOINT_HEALTH_PROBE_TIMEOUT = 2 * time.Second
+
+// an orbs client that spreads requests over all the endpoints of the environment
+// requests that fail to connect are retried on the next endpoint, transactions only when the node surely did not receive them
+// when all endpoints fail the request is retried with backoff according to the retry policy
+type gammaClient struct {
+	*orbs.OrbsClient
+	endpoints     []string
+	primary       int // index of the endpoint transactions are sent to
+	next          int // index of the endpoint the next query is sent to when round-robin
+	roundRobin    bool
+	retry         *retryPolicy // nil sends every request once
+	healthChecked bool         // endpoints are probed once per run, on the first failover
+}
+
+func newGammaClient(endpoints []string, virtualChainId uint32, networkType codec.NetworkType, roundRobin bool, retry *retryPolicy) *gammaClient {
+	return &gammaClient{
+		OrbsClient: orbs.NewClient(endpoints[0], virtualChainId, networkType),
+		endpoints:  endpoints,
+		roundRobin: roundRobin,
+		retry:      retry,
+	}
+}
+
+// a retry re-sends the identical signed payload, so the TxId is unchanged and the network reports it as a duplicate
+// if an earlier attempt did reach it, in which case the response is replaced by the status of the original transaction
+func (c *gammaClient) SendTransaction(payload []byte, txId string) (response *codec.SendTransactionResponse, err error) {
+	err = c.withRetries(false, func() error {
+		response, err = c.OrbsClient.SendTransaction(payload)
+		return err
+	})
+	if err != nil || response == nil || !isDuplicateTransactionStatus(response.TransactionStatus) {
+		return
+	}
+
+	logToStderr("Transaction %s was already received by the network, reporting its status.", txId)
+	return c.getOriginalTransactionStatus(txId)
+}
+
+func (c *gammaClient) SendQuery(payload []byte) (response *codec.RunQueryResponse, err error) {
+	err = c.withRetries(true, func() error {
+		response, err = c.OrbsClient.SendQuery(payload)
+		return err
+	})
+	return
+}
+
+func (c *gammaClient) GetTransactionStatus(txId string) (response *codec.GetTransactionStatusResponse, err error) {
+	err = c.withRetries(true, func() error {
+		response, err = c.OrbsClient.GetTransactionStatus(txId)
+		return err
+	})
+	return
+}
+
+func (c *gammaClient) GetTransactionReceiptProof(txId string) (response *codec.GetTransactionReceiptProofResponse, err error) {
+	err = c.withRetries(true, func() error {
+		response, err = c.OrbsClient.GetTransactionReceiptProof(txId)
+		return err
+	})
+	return
+}
+
+// polls until the original transaction is no longer pending or the retry policy is exhausted
+func (c *gammaClient) getOriginalTransactionStatus(txId string) (*codec.SendTransactionResponse, error) {
+	for retry := 1; ; retry++ {
+		status, err := c.GetTransactionStatus(txId)
+		if err != nil {
+			return nil, err
+		}
+		if status.TransactionStatus != codec.TRANSACTION_STATUS_PENDING || c.retry == nil || retry >= c.retry.maxAttempts {
+			return &codec.SendTransactionResponse{TransactionResponse: status.TransactionResponse}, nil
+		}
+		time.Sleep(c.retry.delay(retry))
+	}
+}
+
+func isDuplicateTransactionStatus(status codec.TransactionStatus) bool {
+	return status == codec.TRANSACTION_STATUS_DUPLICATE_TRANSACTION_ALREADY_COMMITTED || status == codec.TRANSACTION_STATUS_DUPLICATE_TRANSACTION_ALREADY_PENDING
+}
+
+// connection errors and timeouts are retried, other errors are answers of the node that a retry would not change
+func (c *gammaClient) withRetries(isRead bool, request func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := c.withFailover(isRead, request)
+		if err == nil || !isConnectionError(err) || c.retry == nil || attempt >= c.retry.maxAttempts {
+			return err
+		}
+
+		delay := c.retry.delay(attempt)
+		logToStderr("Request failed: %s\nRetrying in %s (attempt %d of %d).", err.Error(), delay.Round(time.Millisecond), attempt+1, c.retry.maxAttempts)
+		time.Sleep(delay)
+	}
+}
+
+// read requests can be safely repeated on another node, a transaction only if it never reached the node
+func (c *gammaClient) withFailover(isRead bool, request func() error) error {
+	index := c.primary
+	if isRead && c.roundRobin {
+		index = c.next
+		c.next = (c.next + 1) % len(c.endpoints)
+	}
+
+	var err error
+	for attempt := 0; attempt < len(c.endpoints); attempt++ {
+		c.Endpoint = c.endpoints[index]
+		err = request()
+
+		canFailOver := isConnectionError(err) && (isRead || isNotReceivedError(err))
+		if !canFailOver || attempt == len(c.endpoints)-1 {
+			break
+		}
+
+		if !c.healthChecked {
+			index = c.sortByHealthAfterFailure(index)
+		}
+		nextIndex := (index + 1) % len(c.endpoints)
+		logToStderr("Node at %s is unreachable, failing over to %s.", c.endpoints[index], c.endpoints[nextIndex])
+		if index == c.primary {
+			c.primary = nextIndex
+		}
+		index = nextIndex
+	}
+
+	if err == nil && len(c.endpoints) > 1 {
+		logToStderr("Answered by node at %s.", c.Endpoint)
+	}
+	return err
+}
+
+// probes the other endpoints so failover skips the ones that are down, runs without failures are not slowed by probes
+// the failed endpoint is moved last and its new index returned
+func (c *gammaClient) sortByHealthAfterFailure(failed int) int {
+	c.healthChecked = true
+	primary := c.endpoints[c.primary]
+	others := append(append([]string{}, c.endpoints[:failed]...), c.endpoints[failed+1:]...)
+	c.endpoints = append(sortEndpointsByHealth(others), c.endpoints[failed])
+	for i, endpoint := range c.endpoints {
+		if endpoint == primary {
+			c.primary = i
+		}
+	}
+	return len(c.endpoints) - 1
+}
+
+// healthy endpoints first, keeping the configured order otherwise
+func sortEndpointsByHealth(endpoints []string) []string {
+	healthy := make([]bool, len(endpoints))
+	var wg sync.WaitGroup
+	for i, endpoint := range endpoints {
+		wg.Add(1)
+		go func(i int, endpoint string) {
+			defer wg.Done()
+			if err := probeEndpoint(endpoint); err != nil {
+				logToStderr("WARNING: node at %s failed health probe: %s", endpoint, err.Error())
+				return
+			}
+			healthy[i] = true
+		}(i, endpoint)
+	}
+	wg.Wait()
+
+	var res, unhealthy []string
+	for i, endpoint := range endpoints {
+		if healthy[i] {
+			res = append(res, endpoint)
+		} else {
+			unhealthy = append(unhealthy, endpoint)
+		}
+	}
+	return append(res, unhealthy...)
+}
+
+// any http response means the node is up, the actual api call will report other problems
+// probes go through the environment http settings (tls, proxy, headers) like the api calls
+func probeEndpoint(endpoint string) error {
+	client := &http.Client{Timeout: ENDPOINT_HEALTH_PROBE_TIMEOUT, Transport: http.DefaultClient.Transport}
+	res, err := client.Get(endpoint)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+func isConnectionError(err error) bool {
+	switch err := errors.Cause(err).(type) {
+	case *url.Error:
+		return !isTlsError(err.Err)
+	case *net.OpError:
+		return err.Op == "dial" || err.Op == "read"
+	case net.Error:
+		return err.Timeout()
+	case syscall.Errno:
+		return err == syscall.ECONNREFUSED
+	default:
+		return err == orbs.NoConnectionError
+	}
+}
+
+// certificate and handshake failures fail the same way on every attempt and every node
+func isTlsError(err error) bool {
+	switch err.(type) {
+	case x509.CertificateInvalidError, x509.HostnameError, x509.UnknownAuthorityError, tls.RecordHeaderError:
+		return true
+	default:
+		// newer go versions wrap these (eg. tls.CertificateVerificationError) and tls alerts are unexported
+		return err != nil && (strings.Contains(err.Error(), "x509: ") || strings.Contains(err.Error(), "tls: "))
+	}
+}
+
+// the request did not reach the node (refused, unresolvable or not serving the api), so resending it elsewhere cannot duplicate it
+func isNotReceivedError(err error) bool {
+	cause := errors.Cause(err)
+	if urlErr, ok := cause.(*url.Error); ok {
+		cause = urlErr.Err
+	}
+	switch cause := cause.(type) {
+	case *net.OpError:
+		return cause.Op == "dial"
+	case *net.DNSError:
+		return true
+	case syscall.Errno:
+		return cause == syscall.ECONNREFUSED
+	default:
+		return cause == orbs.NoConnectionError
+	}
+}
+
+// keeps stdout clean for json output
+func logToStderr(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format, args...)
+	fmt.Fprintf(os.Stderr, "\n")
+}
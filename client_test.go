@@ -0,0 +1,149 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"crypto/x509"
+	"github.com/orbs-network/orbs-client-sdk-go/orbs"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func closedEndpoint(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err, "listener should be created")
+	endpoint := "http://" + listener.Addr().String()
+	listener.Close()
+	return endpoint
+}
+
+func TestSortEndpointsByHealth(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	down := closedEndpoint(t)
+
+	require.Equal(t, []string{server.URL, down}, sortEndpointsByHealth([]string{down, server.URL}), "healthy endpoints should come first")
+}
+
+func TestGammaClientFailover(t *testing.T) {
+	dialErr := errors.Wrap(&url.Error{Op: "Post", URL: "http://a", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}, "failed sending http post")
+	readErr := errors.Wrap(&url.Error{Op: "Post", URL: "http://a", Err: &net.OpError{Op: "read", Err: errors.New("connection reset")}}, "failed reading http response")
+	notFoundErr := errors.Wrap(orbs.NoConnectionError, "http 404 not found")
+
+	tests := []struct {
+		name       string
+		isRead     bool
+		errs       map[string]error
+		expected   []string
+		expectErr  bool
+		newPrimary string
+	}{
+		{"QueryFailsOverOnRead", true, map[string]error{"a": readErr}, []string{"a", "b"}, false, "b"},
+		{"TxFailsOverOnDial", false, map[string]error{"a": dialErr}, []string{"a", "b"}, false, "b"},
+		{"TxFailsOverOnNotFound", false, map[string]error{"a": notFoundErr}, []string{"a", "b"}, false, "b"},
+		{"TxDoesNotResendAfterRead", false, map[string]error{"a": readErr}, []string{"a"}, true, "a"},
+		{"AllDown", true, map[string]error{"a": dialErr, "b": dialErr, "c": dialErr}, []string{"a", "b", "c"}, true, "c"},
+		{"NotConnectionError", true, map[string]error{"a": errors.New("bad request")}, []string{"a"}, true, "a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &gammaClient{OrbsClient: &orbs.OrbsClient{}, endpoints: []string{"a", "b", "c"}}
+
+			var called []string
+			err := client.withFailover(tt.isRead, func() error {
+				called = append(called, client.Endpoint)
+				return tt.errs[client.Endpoint]
+			})
+
+			require.Equal(t, tt.expectErr, err != nil, "unexpected error %v", err)
+			require.Equal(t, tt.expected, called, "endpoints should be tried in order")
+			require.Equal(t, tt.newPrimary, client.endpoints[client.primary], "unreachable primary should be replaced")
+		})
+	}
+}
+
+func TestGammaClientFailover_ProbesOnFirstFailure(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	down := closedEndpoint(t)
+	dialErr := errors.Wrap(&url.Error{Op: "Post", URL: "http://a", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}, "failed sending http post")
+
+	client := &gammaClient{OrbsClient: &orbs.OrbsClient{}, endpoints: []string{"a", down, server.URL}}
+	var called []string
+	request := func() error {
+		called = append(called, client.Endpoint)
+		if client.Endpoint != server.URL {
+			return dialErr
+		}
+		return nil
+	}
+
+	require.NoError(t, client.withFailover(true, request))
+	require.Equal(t, []string{"a", server.URL}, called, "failover should skip the endpoint that failed its probe")
+	require.Equal(t, []string{server.URL, down, "a"}, client.endpoints, "endpoints should be sorted by health with the failed one last")
+	require.Equal(t, server.URL, client.endpoints[client.primary], "primary should be the healthy endpoint")
+	require.True(t, client.healthChecked, "endpoints should be probed once per run")
+}
+
+func TestGammaClientRoundRobin(t *testing.T) {
+	client := &gammaClient{OrbsClient: &orbs.OrbsClient{}, endpoints: []string{"a", "b", "c"}, roundRobin: true}
+
+	var called []string
+	request := func() error {
+		called = append(called, client.Endpoint)
+		return nil
+	}
+	for i := 0; i < 4; i++ {
+		require.NoError(t, client.withFailover(true, request))
+	}
+	require.NoError(t, client.withFailover(false, request))
+
+	require.Equal(t, []string{"a", "b", "c", "a", "a"}, called, "queries should rotate and transactions should go to the primary")
+}
+
+func TestGammaClientRetries(t *testing.T) {
+	dialErr := errors.Wrap(&url.Error{Op: "Post", URL: "http://a", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}, "failed sending http post")
+	readErr := errors.Wrap(&url.Error{Op: "Post", URL: "http://a", Err: &net.OpError{Op: "read", Err: errors.New("connection reset")}}, "failed reading http response")
+
+	tests := []struct {
+		name      string
+		isRead    bool
+		errs      []error
+		expected  int
+		expectErr bool
+	}{
+		{"QueryRecovers", true, []error{dialErr, readErr}, 3, false},
+		{"TxResentAfterRead", false, []error{readErr}, 2, false},
+		{"GivesUp", true, []error{dialErr, dialErr, dialErr, dialErr}, 3, true},
+		{"NotConnectionError", false, []error{errors.New("bad request")}, 1, true},
+		{"UnknownAuthority", true, []error{&url.Error{Op: "Post", URL: "https://a", Err: x509.UnknownAuthorityError{}}}, 1, true},
+		{"TlsAlert", true, []error{&url.Error{Op: "Post", URL: "https://a", Err: errors.New("remote error: tls: handshake failure")}}, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &gammaClient{OrbsClient: &orbs.OrbsClient{}, endpoints: []string{"a"}, retry: &retryPolicy{maxAttempts: 3, backoff: time.Millisecond, maxBackoff: time.Millisecond}}
+
+			calls := 0
+			err := client.withRetries(tt.isRead, func() error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			})
+
+			require.Equal(t, tt.expectErr, err != nil, "unexpected error %v", err)
+			require.Equal(t, tt.expected, calls, "request should be attempted up to the policy limit")
+		})
+	}
+}
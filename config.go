@@ -7,63 +7,297 @@
 package main
 
 import (
+	"flag"
 	"github.com/orbs-network/gamma-cli/jsoncodec"
+	"github.com/pkg/errors"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 )
 
-func getDefaultLocalConfig() *jsoncodec.ConfEnv {
-	return &jsoncodec.ConfEnv{
-		VirtualChain: 42,
-		Endpoints:    []string{"localhost"},
+const (
+	ENV_VAR_GAMMA_ENV      = "GAMMA_ENV"
+	ENV_VAR_GAMMA_ENDPOINT = "GAMMA_ENDPOINT"
+	ENV_VAR_GAMMA_VCHAIN   = "GAMMA_VCHAIN"
+
+	CONFIG_SOURCE_DEFAULTS = "built-in defaults"
+	CONFIG_SOURCE_FLAG     = "-env flag"
+	CONFIG_SOURCE_DEFAULT  = "default"
+)
+
+var configFileExtensions = []string{".json", ".yaml", ".yml", ".toml"}
+
+var resolvedConfig *jsoncodec.ResolvedConf
+var configFilenames []string // files merged into resolvedConfig, lowest precedence first
+var envSource = CONFIG_SOURCE_DEFAULT
+var envContractAliases map[string]string // ContractAliases of the environment
+
+func getDefaultLocalConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"VirtualChain": 42,
+		"Endpoints":    []interface{}{"localhost"},
 	}
 }
 
-func getDefaultExperimentalConfig() *jsoncodec.ConfEnv {
-	return &jsoncodec.ConfEnv{
-		VirtualChain: 42,
-		Endpoints:    []string{"localhost"},
-		Experimental: true,
+func getDefaultExperimentalConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"VirtualChain": 42,
+		"Endpoints":    []interface{}{"localhost"},
+		"Experimental": true,
+	}
+}
+
+// the environment is selected by -env, then GAMMA_ENV, then the DefaultEnvironment of the config files (see 'config use')
+// and its defaults apply to the options not given explicitly
+func configureEnvironment() {
+	// invalid config files are reported once a command uses the config
+	var conf *jsoncodec.ResolvedConf
+	if layers, _, err := loadConfigFileLayers(); err == nil {
+		conf, _ = jsoncodec.MergeConfLayers(layers...)
+	}
+
+	if isFlagSet("env") {
+		envSource = CONFIG_SOURCE_FLAG
+	} else if env := os.Getenv(ENV_VAR_GAMMA_ENV); env != "" {
+		*flagEnv = env
+		envSource = ENV_VAR_GAMMA_ENV
+	} else if conf != nil && conf.DefaultEnvironment != "" {
+		*flagEnv = conf.DefaultEnvironment
+		envSource = conf.Source("DefaultEnvironment")
+	}
+
+	if conf != nil {
+		applyEnvironmentDefaults(conf.Environments[*flagEnv])
 	}
 }
 
-func getDefaultConfigForEnv(env string) *jsoncodec.ConfEnv {
-	if env == LOCAL_ENV_ID {
-		return getDefaultLocalConfig()
+func applyEnvironmentDefaults(env *jsoncodec.ConfEnv) {
+	if env == nil {
+		return
+	}
+	if env.Signer != "" && !isFlagSet("signer") {
+		*flagSigner = env.Signer
+	}
+	if env.Keys != "" && !isFlagSet("keys") {
+		*flagKeyFile = env.Keys
 	}
-	if env == EXPERIMENTAL_ENV_ID {
-		return getDefaultExperimentalConfig()
+	if env.PrismPort != 0 && !isFlagSet("prismPort") {
+		*flagPrismPort = env.PrismPort
 	}
-	return nil
+	envContractAliases = env.ContractAliases
+	envGammaImage = env.GammaImage
+	envPrismImage = env.PrismImage
+}
+
+func isFlagSet(name string) bool {
+	found := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			found = true
+		}
+	})
+	return found
 }
 
 func getEnvironmentFromConfigFile(env string) *jsoncodec.ConfEnv {
-	bytes, err := ioutil.ReadFile(*flagConfigFile)
-	if err != nil {
-		if res := getDefaultConfigForEnv(env); res != nil {
-			return res
+	conf := getResolvedConfig()
+
+	confEnv, found := conf.Environments[env]
+	if !found || confEnv == nil {
+		if len(configFilenames) == 0 {
+			die("Environment with id '%s' not found, no config file found in %s.", env, strings.Join(getConfigSearchPaths(), ", "))
 		}
-		die("Could not open config file '%s' containing environment details.\n\n%s", *flagConfigFile, err.Error())
+		die("Environment with id '%s' not found in config files %s.", env, strings.Join(configFilenames, ", "))
 	}
 
-	confFile, err := jsoncodec.UnmarshalConfFile(bytes)
+	return confEnv
+}
+
+// layers from lowest to highest precedence: built-in defaults, config files (see getConfigSearchPaths) and environment variables
+func getResolvedConfig() *jsoncodec.ResolvedConf {
+	if resolvedConfig != nil {
+		return resolvedConfig
+	}
+
+	layers, filenames, err := loadConfigFileLayers()
 	if err != nil {
-		die("Failed parsing config json file '%s'.\n\n%s", *flagConfigFile, err.Error())
+		die(err.Error())
 	}
+	configFilenames = filenames
+	layers = append(layers, getEnvironmentVariablesConfigLayers(*flagEnv)...)
 
-	if len(confFile.Environments) == 0 {
-		if res := getDefaultConfigForEnv(env); res != nil {
-			return res
+	conf, err := jsoncodec.MergeConfLayers(layers...)
+	if err != nil {
+		die("Could not merge config files %s.\n\n%s", strings.Join(configFilenames, ", "), err.Error())
+	}
+	resolvedConfig = conf
+	return resolvedConfig
+}
+
+// built-in defaults followed by the config files that exist, lowest precedence first
+func loadConfigFileLayers() ([]*jsoncodec.ConfLayer, []string, error) {
+	layers := []*jsoncodec.ConfLayer{
+		jsoncodec.NewConfEnvLayer(CONFIG_SOURCE_DEFAULTS, LOCAL_ENV_ID, getDefaultLocalConfig()),
+		jsoncodec.NewConfEnvLayer(CONFIG_SOURCE_DEFAULTS, EXPERIMENTAL_ENV_ID, getDefaultExperimentalConfig()),
+	}
+
+	var filenames []string
+	searchPaths := getConfigSearchPaths()
+	for i := len(searchPaths) - 1; i >= 0; i-- {
+		filename := searchPaths[i]
+		bytes, err := ioutil.ReadFile(filename)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, nil, errors.Errorf("Could not open config file '%s' containing environment details.\n\n%s", filename, err.Error())
 		}
-		die("Key 'Environments' does not contain data in config file '%s'.", *flagConfigFile)
+
+		layer, err := jsoncodec.UnmarshalConfLayer(filename, bytes)
+		if err != nil {
+			return nil, nil, errors.Errorf("Failed parsing config file '%s'.\n\n%s", filename, err.Error())
+		}
+		layers = append(layers, layer)
+		filenames = append(filenames, filename)
 	}
+	return layers, filenames, nil
+}
 
-	confEnv, found := confFile.Environments[env]
-	if !found {
-		if res := getDefaultConfigForEnv(env); res != nil {
+// an explicit -config is the only file, otherwise the config file found in the current directory, every parent directory up to
+// the repository root, $XDG_CONFIG_HOME/gamma and ~/.orbs, from highest to lowest precedence
+func getConfigSearchPaths() []string {
+	if isFlagSet("config") {
+		return []string{*flagConfigFile}
+	}
+
+	var dirs []string
+	if cwd, err := os.Getwd(); err == nil {
+		dirs = append(dirs, getRepositoryDirs(cwd)...)
+	}
+	if configHome := os.Getenv("XDG_CONFIG_HOME"); configHome != "" {
+		dirs = append(dirs, filepath.Join(configHome, "gamma"))
+	} else if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".config", "gamma"))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".orbs"))
+	}
+
+	var res []string
+	for _, dir := range dirs {
+		res = append(res, findConfigFileInDir(dir))
+	}
+	return res
+}
+
+// dir and its parents up to the root of the enclosing git repository, or only dir outside of a repository
+func getRepositoryDirs(dir string) []string {
+	var res []string
+	for current := dir; ; {
+		res = append(res, current)
+		if doesFileExist(filepath.Join(current, ".git")) {
 			return res
 		}
-		die("Environment with id '%s' not found in config file '%s'.", env, *flagKeyFile)
+		parent := filepath.Dir(current)
+		if parent == current {
+			return []string{dir}
+		}
+		current = parent
 	}
+}
 
-	return confEnv
+// the config file of dir in any supported format, or the json one if there is none
+func findConfigFileInDir(dir string) string {
+	base := strings.TrimSuffix(CONFIG_FILENAME, filepath.Ext(CONFIG_FILENAME))
+	for _, ext := range configFileExtensions {
+		if filename := filepath.Join(dir, base+ext); doesFileExist(filename) {
+			return filename
+		}
+	}
+	return filepath.Join(dir, CONFIG_FILENAME)
+}
+
+func getEnvironmentVariablesConfigLayers(env string) []*jsoncodec.ConfLayer {
+	var layers []*jsoncodec.ConfLayer
+	if value := os.Getenv(ENV_VAR_GAMMA_ENDPOINT); value != "" {
+		var endpoints []interface{}
+		for _, endpoint := range strings.Split(value, ",") {
+			if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+				endpoints = append(endpoints, endpoint)
+			}
+		}
+		layers = append(layers, jsoncodec.NewConfEnvLayer(ENV_VAR_GAMMA_ENDPOINT, env, map[string]interface{}{"Endpoints": endpoints}))
+	}
+	if value := os.Getenv(ENV_VAR_GAMMA_VCHAIN); value != "" {
+		vchain, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			die("Environment variable %s should be a virtual chain id, current value: '%s'.", ENV_VAR_GAMMA_VCHAIN, value)
+		}
+		layers = append(layers, jsoncodec.NewConfEnvLayer(ENV_VAR_GAMMA_VCHAIN, env, map[string]interface{}{"VirtualChain": vchain}))
+	}
+	return layers
+}
+
+// the config file that set the field of the current environment, relative paths in it are relative to that file
+func getConfigSource(field string) string {
+	return getResolvedConfig().Source("Environments." + *flagEnv + "." + field)
+}
+
+func commandConfig(requiredOptions []string) {
+	subcommand := requiredOptions[0]
+
+	args := requiredOptions[1:]
+
+	switch subcommand {
+	case "show":
+		commandConfigShow()
+	case "init":
+		commandConfigInit()
+	case "add-env":
+		requireSubcommandArgs("config add-env", args, "<ENVIRONMENT_ID>")
+		commandConfigAddEnv(args[0])
+	case "remove-env":
+		requireSubcommandArgs("config remove-env", args, "<ENVIRONMENT_ID>")
+		commandConfigRemoveEnv(args[0])
+	case "use":
+		requireSubcommandArgs("config use", args, "<ENVIRONMENT_ID>")
+		commandConfigUse(args[0])
+	case "validate":
+		commandConfigValidate()
+	default:
+		die("Unknown config subcommand '%s'.\n\nSupported subcommands are: show init add-env remove-env use validate", subcommand)
+	}
+}
+
+func commandConfigShow() {
+	conf := getResolvedConfig()
+
+	if !*flagConfigResolved {
+		log("Config files (highest precedence first):")
+		for _, filename := range getConfigSearchPaths() {
+			status := "not found"
+			if doesFileExist(filename) {
+				status = "loaded"
+			}
+			log("  %s (%s)", filename, status)
+		}
+		log("")
+
+		var envs []string
+		for env := range conf.Environments {
+			envs = append(envs, env)
+		}
+		sort.Strings(envs)
+		log("Environments: %s", strings.Join(envs, ", "))
+		return
+	}
+
+	getEnvironmentFromConfigFile(*flagEnv)
+	log("Environment: %s (%s)", *flagEnv, envSource)
+	for _, value := range conf.EnvValues(*flagEnv) {
+		log("  %s = %s (%s)", value.Field, value.Value, value.Source)
+	}
 }
@@ -0,0 +1,231 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"github.com/orbs-network/gamma-cli/jsoncodec"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"math"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+func commandConfigInit() {
+	filename := CONFIG_FILENAME
+	if isFlagSet("config") {
+		filename = *flagConfigFile
+	}
+	if doesFileExist(filename) {
+		die("Config file '%s' already exists, use 'gamma-cli config add-env' to add environments to it.", filename)
+	}
+
+	confFile := jsoncodec.NewEditableConfFile()
+	confFile.SetEnvironment(LOCAL_ENV_ID, getDefaultLocalConfig())
+	writeEditableConfFile(filename, confFile)
+	log("Config file '%s' created with environment '%s'.", filename, LOCAL_ENV_ID)
+}
+
+func commandConfigAddEnv(envId string) {
+	var endpoints []interface{}
+	for _, value := range flagConfigEndpoints {
+		for _, endpoint := range strings.Split(value, ",") {
+			if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+				endpoints = append(endpoints, endpoint)
+			}
+		}
+	}
+	if len(endpoints) == 0 {
+		die("Command 'config add-env' requires at least one -endpoint.")
+	}
+	if *flagVirtualChain == 0 || *flagVirtualChain > math.MaxUint32 {
+		die("Command 'config add-env' requires -vchain with the virtual chain id of the environment.")
+	}
+	for _, endpoint := range endpoints {
+		if err := validateEndpoint(endpoint.(string)); err != nil {
+			die("Invalid -endpoint.\n\n%s", err.Error())
+		}
+	}
+
+	filename, confFile := readConfigWriteTarget()
+	if confFile.HasEnvironment(envId) {
+		die("Environment '%s' already exists in config file '%s', use 'gamma-cli config remove-env %s' first to replace it.", envId, filename, envId)
+	}
+
+	confFile.SetEnvironment(envId, map[string]interface{}{
+		"VirtualChain": *flagVirtualChain,
+		"Endpoints":    endpoints,
+	})
+	writeEditableConfFile(filename, confFile)
+	log("Environment '%s' added to config file '%s'.", envId, filename)
+}
+
+func commandConfigRemoveEnv(envId string) {
+	filename, confFile := readConfigWriteTarget()
+	if !confFile.RemoveEnvironment(envId) {
+		die("Environment '%s' not found in config file '%s'.", envId, filename)
+	}
+	writeEditableConfFile(filename, confFile)
+	log("Environment '%s' removed from config file '%s'.", envId, filename)
+}
+
+func commandConfigUse(envId string) {
+	if _, found := getResolvedConfig().Environments[envId]; !found {
+		die("Environment with id '%s' not found in config files %s.", envId, strings.Join(configFilenames, ", "))
+	}
+
+	filename, confFile := readConfigWriteTarget()
+	confFile.SetDefaultEnvironment(envId)
+	writeEditableConfFile(filename, confFile)
+	log("Environment '%s' is now the default in config file '%s'.", envId, filename)
+}
+
+func commandConfigValidate() {
+	errorCount := 0
+	report := func(level string, format string, args ...interface{}) {
+		if level == "ERROR" {
+			errorCount++
+		}
+		log(level+": "+format, args...)
+	}
+
+	for _, filename := range getConfigSearchPaths() {
+		bytes, err := ioutil.ReadFile(filename)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				report("ERROR", "could not open config file '%s': %s", filename, err.Error())
+			}
+			continue
+		}
+		if _, err := jsoncodec.UnmarshalConfLayer(filename, bytes); err != nil {
+			report("ERROR", "%s", err.Error())
+			continue
+		}
+		for _, warning := range jsoncodec.UnknownConfFields(filename, bytes) {
+			report("WARNING", "%s", warning.Error())
+		}
+	}
+	if errorCount > 0 {
+		die("Config files have %d errors.", errorCount)
+	}
+
+	conf := getResolvedConfig()
+	var envIds []string
+	for envId := range conf.Environments {
+		envIds = append(envIds, envId)
+	}
+	sort.Strings(envIds)
+	for _, envId := range envIds {
+		for _, err := range validateConfEnv(envId, conf.Environments[envId]) {
+			report("ERROR", "environment '%s' in '%s': %s", envId, conf.Source("Environments."+envId), err.Error())
+		}
+	}
+	if conf.DefaultEnvironment != "" && conf.Environments[conf.DefaultEnvironment] == nil {
+		report("ERROR", "DefaultEnvironment '%s' in '%s' is not defined", conf.DefaultEnvironment, conf.Source("DefaultEnvironment"))
+	}
+
+	if errorCount > 0 {
+		die("Config has %d errors.", errorCount)
+	}
+	log("Config is valid, environments: %s", strings.Join(envIds, ", "))
+}
+
+// the problems that would otherwise only surface when a command uses the environment
+func validateConfEnv(envId string, env *jsoncodec.ConfEnv) []error {
+	if env == nil {
+		return []error{errors.New("environment is null")}
+	}
+
+	var res []error
+	if env.VirtualChain == 0 {
+		res = append(res, errors.New("VirtualChain is missing"))
+	}
+	if len(env.Endpoints) == 0 {
+		res = append(res, errors.New("Endpoints is missing or empty"))
+	}
+	for _, endpoint := range env.Endpoints {
+		if err := validateEndpoint(endpoint); err != nil {
+			res = append(res, err)
+		}
+	}
+	if _, err := parseNetworkType(envId, env); err != nil {
+		res = append(res, err)
+	}
+	if env.Http != nil {
+		if _, err := newHttpClient(env.Http, ""); err != nil {
+			res = append(res, errors.Wrap(err, "invalid Http settings"))
+		}
+	}
+	if _, err := newRetryPolicy(env.Retry); err != nil {
+		res = append(res, errors.Wrap(err, "invalid Retry settings"))
+	}
+	if env.PrismPort < 0 || env.PrismPort > math.MaxUint16 {
+		res = append(res, errors.Errorf("PrismPort should be a port number, current value: %d", env.PrismPort))
+	}
+	if _, err := newDockerImage(GAMMA_DOCKER_REPO, env.GammaImage, ""); err != nil {
+		res = append(res, errors.Wrap(err, "invalid GammaImage settings"))
+	}
+	if _, err := newDockerImage(PRISM_DOCKER_REPO, env.PrismImage, ""); err != nil {
+		res = append(res, errors.Wrap(err, "invalid PrismImage settings"))
+	}
+	for alias, contractName := range env.ContractAliases {
+		if contractName == "" {
+			res = append(res, errors.Errorf("ContractAliases should map '%s' to a contract name", alias))
+		}
+	}
+	return res
+}
+
+func validateEndpoint(endpoint string) error {
+	if endpoint == "localhost" {
+		return nil
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Errorf("endpoint '%s' should be 'localhost' or an http(s) url (eg. http://node1.example.com)", endpoint)
+	}
+	return nil
+}
+
+// an explicit -config, otherwise the config file with the highest precedence, otherwise a new one in the current directory
+func readConfigWriteTarget() (string, *jsoncodec.EditableConfFile) {
+	filename := CONFIG_FILENAME
+	for _, path := range getConfigSearchPaths() {
+		if doesFileExist(path) || isFlagSet("config") {
+			filename = path
+			break
+		}
+	}
+
+	if !doesFileExist(filename) {
+		return filename, jsoncodec.NewEditableConfFile()
+	}
+	bytes, err := ioutil.ReadFile(filename)
+	if err != nil {
+		die("Could not open config file '%s'.\n\n%s", filename, err.Error())
+	}
+	confFile, err := jsoncodec.UnmarshalEditableConfFile(filename, bytes)
+	if err != nil {
+		die("Failed parsing config file '%s'.\n\n%s", filename, err.Error())
+	}
+	return filename, confFile
+}
+
+func writeEditableConfFile(filename string, confFile *jsoncodec.EditableConfFile) {
+	bytes, err := jsoncodec.MarshalEditableConfFile(filename, confFile)
+	if err != nil {
+		die("Could not encode config file '%s'.\n\n%s", filename, err.Error())
+	}
+	if !strings.HasSuffix(string(bytes), "\n") {
+		bytes = append(bytes, '\n')
+	}
+	if err := ioutil.WriteFile(filename, bytes, 0644); err != nil {
+		die("Could not write config file '%s'.\n\n%s", filename, err.Error())
+	}
+}
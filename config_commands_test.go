@@ -0,0 +1,60 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"github.com/orbs-network/gamma-cli/jsoncodec"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestValidateConfEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      *jsoncodec.ConfEnv
+		expected []string
+	}{
+		{"Valid", &jsoncodec.ConfEnv{VirtualChain: 1000, Endpoints: []string{"http://node1", "https://node2:8080"}}, nil},
+		{"Null", nil, []string{"environment is null"}},
+		{"MissingFields", &jsoncodec.ConfEnv{}, []string{"VirtualChain is missing", "Endpoints is missing or empty"}},
+		{"InvalidEndpoint", &jsoncodec.ConfEnv{VirtualChain: 1000, Endpoints: []string{"node1:8080"}}, []string{"endpoint 'node1:8080' should be 'localhost' or an http(s) url (eg. http://node1.example.com)"}},
+		{"InvalidNetworkType", &jsoncodec.ConfEnv{VirtualChain: 1000, Endpoints: []string{"http://node1"}, NetworkType: "prod"}, []string{"NetworkType should be 'main' or 'test', current value: 'prod'"}},
+		{"InvalidHttp", &jsoncodec.ConfEnv{VirtualChain: 1000, Endpoints: []string{"http://node1"}, Http: &jsoncodec.ConfHttp{Timeout: "soon"}}, []string{"invalid Http settings: Timeout should be a duration (eg. 30s), current value: 'soon'"}},
+		{"InvalidPrismPort", &jsoncodec.ConfEnv{VirtualChain: 1000, Endpoints: []string{"http://node1"}, PrismPort: 70000}, []string{"PrismPort should be a port number, current value: 70000"}},
+		{"EmptyContractAlias", &jsoncodec.ConfEnv{VirtualChain: 1000, Endpoints: []string{"http://node1"}, ContractAliases: map[string]string{"Token": ""}}, []string{"ContractAliases should map 'Token' to a contract name"}},
+		{"InvalidGammaImage", &jsoncodec.ConfEnv{VirtualChain: 1000, Endpoints: []string{"http://node1"}, GammaImage: &jsoncodec.ConfImage{Registry: "registry.example.com"}}, []string{"invalid GammaImage settings: Registry should be an http(s) url (eg. https://registry.example.com), current value: 'registry.example.com'"}},
+		{"InvalidRetry", &jsoncodec.ConfEnv{VirtualChain: 1000, Endpoints: []string{"http://node1"}, Retry: &jsoncodec.ConfRetry{MaxAttempts: -1}}, []string{"invalid Retry settings: MaxAttempts should be at least 1, current value: -1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var messages []string
+			for _, err := range validateConfEnv("testnet", tt.env) {
+				messages = append(messages, err.Error())
+			}
+			require.Equal(t, tt.expected, messages)
+		})
+	}
+}
+
+func TestWriteEditableConfFile_TrailingNewline(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gamma-cli-config")
+	require.NoError(t, err, "temp dir should be created")
+	defer os.RemoveAll(dir)
+
+	for _, filename := range []string{"orbs-gamma-config.json", "orbs-gamma-config.yaml", "orbs-gamma-config.toml"} {
+		confFile := jsoncodec.NewEditableConfFile()
+		confFile.SetEnvironment("testnet", map[string]interface{}{"VirtualChain": uint(1000), "Endpoints": []interface{}{"http://a"}})
+		writeEditableConfFile(path.Join(dir, filename), confFile)
+
+		bytes, err := ioutil.ReadFile(path.Join(dir, filename))
+		require.NoError(t, err, "config file should be written")
+		require.True(t, len(bytes) > 0 && bytes[len(bytes)-1] == '\n', "%s should end with a newline", filename)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"github.com/orbs-network/gamma-cli/jsoncodec"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetRepositoryDirs(t *testing.T) {
+	root, err := ioutil.TempDir("", "gamma-cli-config")
+	require.NoError(t, err, "temp dir should be created")
+	defer os.RemoveAll(root)
+
+	repo := filepath.Join(root, "repo")
+	nested := filepath.Join(repo, "contracts", "token")
+	require.NoError(t, os.MkdirAll(nested, 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, ".git"), 0755))
+
+	require.Equal(t, []string{nested, filepath.Join(repo, "contracts"), repo}, getRepositoryDirs(nested), "parents should be searched up to the repository root")
+	require.Equal(t, []string{root}, getRepositoryDirs(root), "only the directory itself should be searched outside of a repository")
+}
+
+func TestFindConfigFileInDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gamma-cli-config")
+	require.NoError(t, err, "temp dir should be created")
+	defer os.RemoveAll(dir)
+
+	require.Equal(t, filepath.Join(dir, CONFIG_FILENAME), findConfigFileInDir(dir), "json file should be reported when there is none")
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "orbs-gamma-config.yaml"), []byte("Environments: {}"), 0644))
+	require.Equal(t, filepath.Join(dir, "orbs-gamma-config.yaml"), findConfigFileInDir(dir))
+}
+
+func TestApplyEnvironmentDefaults(t *testing.T) {
+	prevSigner, prevKeys, prevPrismPort := *flagSigner, *flagKeyFile, *flagPrismPort
+	defer func() {
+		*flagSigner, *flagKeyFile, *flagPrismPort = prevSigner, prevKeys, prevPrismPort
+		envContractAliases = nil
+	}()
+
+	applyEnvironmentDefaults(&jsoncodec.ConfEnv{
+		Signer:          "deployer",
+		Keys:            "/team/testnet-keys.json",
+		PrismPort:       3100,
+		ContractAliases: map[string]string{"Token": "MyToken"},
+	})
+
+	require.Equal(t, "deployer", *flagSigner)
+	require.Equal(t, "/team/testnet-keys.json", *flagKeyFile)
+	require.Equal(t, 3100, *flagPrismPort)
+	require.Equal(t, map[string]string{"Token": "MyToken"}, envContractAliases)
+}
@@ -0,0 +1,161 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"github.com/orbs-network/gamma-cli/jsoncodec"
+	"github.com/orbs-network/orbs-client-sdk-go/orbs"
+	"sort"
+	"strconv"
+)
+
+func commandContractAlias(requiredOptions []string) {
+	subcommand := requiredOptions[0]
+	args := requiredOptions[1:]
+
+	switch subcommand {
+	case "list":
+		commandContractAliasList()
+	case "set":
+		requireSubcommandArgs("contract-alias set", args, "<ALIAS>", "<CONTRACT_NAME>")
+		commandContractAliasSet(args[0], args[1])
+	case "remove":
+		requireSubcommandArgs("contract-alias remove", args, "<ALIAS>")
+		commandContractAliasRemove(args[0])
+	case "resolve":
+		requireSubcommandArgs("contract-alias resolve", args, "<ALIAS>")
+		log("%s", resolveContractAlias(args[0]))
+	default:
+		die("Unknown contract-alias subcommand '%s'.\n\nSupported subcommands are: list set remove resolve", subcommand)
+	}
+}
+
+func commandContractAliasList() {
+	if len(envContractAliases) > 0 {
+		log("ContractAliases of environment '%s' in '%s':", *flagEnv, getConfigSource("ContractAliases"))
+		logContractAliases(envContractAliases)
+	}
+
+	lockEnv := readDeployLock().Env(*flagEnv)
+	if len(lockEnv.Aliases) == 0 {
+		log("No contract aliases for environment '%s' in '%s'.", *flagEnv, *flagDeployLock)
+		return
+	}
+	if len(envContractAliases) > 0 {
+		log("Aliases in '%s':", *flagDeployLock)
+	}
+	logContractAliases(lockEnv.Aliases)
+}
+
+func logContractAliases(aliases map[string]string) {
+	var names []string
+	for alias := range aliases {
+		names = append(names, alias)
+	}
+	sort.Strings(names)
+	for _, alias := range names {
+		log("%s -> %s", alias, aliases[alias])
+	}
+}
+
+func commandContractAliasSet(alias string, contractName string) {
+	lock := readDeployLock()
+	lockEnv := lock.Env(*flagEnv)
+	if existing, found := lockEnv.Aliases[alias]; found {
+		log("Replacing existing alias '%s' of '%s'.", existing, alias)
+	}
+	lockEnv.Aliases[alias] = contractName
+	writeDeployLock(lock)
+
+	log("Contract alias '%s' now resolves to '%s' on environment '%s'.", alias, contractName, *flagEnv)
+}
+
+func commandContractAliasRemove(alias string) {
+	lock := readDeployLock()
+	lockEnv := lock.Env(*flagEnv)
+	if _, found := lockEnv.Aliases[alias]; !found {
+		die("Contract alias '%s' not found for environment '%s' in '%s'.", alias, *flagEnv, *flagDeployLock)
+	}
+	delete(lockEnv.Aliases, alias)
+	writeDeployLock(lock)
+
+	log("Contract alias '%s' removed.", alias)
+}
+
+// returns the deployed contract name of a logical name on the current environment, names without an alias are returned as is
+// the ContractAliases of the environment config are resolved first, then the aliases of the lockfile (eg. MyToken -> MyToken_v2)
+func resolveContractAlias(contractName string) string {
+	if aliased, found := envContractAliases[contractName]; found {
+		contractName = aliased
+	}
+	if !doesFileExist(*flagDeployLock) {
+		return contractName
+	}
+
+	lockEnv := readDeployLock().Env(*flagEnv)
+	if deployedName, found := lockEnv.Aliases[contractName]; found {
+		return deployedName
+	}
+	return contractName
+}
+
+func versionedContractName(contractName string, version int) string {
+	return contractName + "_v" + strconv.Itoa(version)
+}
+
+// deploys the local sources as the next version of -name and points the alias of -name to it in the lockfile
+// with -check the latest version is compared instead, with -if-missing a new version is deployed only if the sources changed
+func commandDeployVersioned(client *gammaClient, signer *jsoncodec.RawKey, processorType uint32, code [][]byte) {
+	contractName := *flagContractName
+	lock := readDeployLock()
+	lockEnv := lock.Env(*flagEnv)
+	latestName, hasLatest := lockEnv.Aliases[contractName]
+
+	if *flagDeployCheck {
+		if !hasLatest {
+			die("No version of contract '%s' was deployed to environment '%s' according to '%s'.", contractName, *flagEnv, *flagDeployLock)
+		}
+		commandDeployCheck(client, signer, latestName, code)
+	}
+
+	if *flagDeployIfMissing && hasLatest {
+		if latest, found := lockEnv.Contracts[latestName]; found && latest.SourceHash == hashSources(code) {
+			log("Contract '%s' is unchanged since version '%s', skipping.", contractName, latestName)
+			exit()
+		}
+	}
+
+	version := lockEnv.Versions[contractName] + 1
+	deployedName := versionedContractName(contractName, version)
+
+	requireMainNetConfirmation(client, "deploy contract '"+deployedName+"'")
+	payload, txId, err := client.CreateDeployTransaction(signer.PublicKey, signer.PrivateKey, deployedName, orbs.ProcessorType(processorType), code...)
+	if err != nil {
+		die("Could not encode payload of the message about to be sent to server.\n\n%s", err.Error())
+	}
+
+	response := sendTransactionAndRequireSuccess(client, payload, txId, "deploy of contract '"+deployedName+"'")
+
+	output, err := jsoncodec.MarshalSendTxResponse(response, txId, getArgsOptions())
+	if err != nil {
+		die("Could not encode send-tx response to json.\n\n%s", err.Error())
+	}
+	log("%s\n", string(output))
+
+	lockEnv.Versions[contractName] = version
+	lockEnv.Aliases[contractName] = deployedName
+	lockEnv.Contracts[deployedName] = &jsoncodec.DeployLockContract{
+		Name:        deployedName,
+		SourceHash:  hashSources(code),
+		TxId:        txId,
+		BlockHeight: strconv.FormatUint(response.BlockHeight, 10),
+	}
+	writeDeployLock(lock)
+
+	log("Contract '%s' deployed as '%s', alias updated in '%s'.", contractName, deployedName, *flagDeployLock)
+	exit()
+}
@@ -0,0 +1,45 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"github.com/orbs-network/gamma-cli/jsoncodec"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestResolveContractAlias(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gamma-cli-lock")
+	require.NoError(t, err, "temp dir should be created")
+	defer os.RemoveAll(dir)
+
+	prevLock, prevEnv := *flagDeployLock, *flagEnv
+	defer func() { *flagDeployLock, *flagEnv = prevLock, prevEnv }()
+	*flagDeployLock = path.Join(dir, DEPLOY_LOCK_FILENAME)
+
+	require.Equal(t, "MyToken", resolveContractAlias("MyToken"), "names should be returned as is without a lockfile")
+
+	lock := &jsoncodec.DeployLock{}
+	lock.Env("testnet").Aliases["MyToken"] = versionedContractName("MyToken", 2)
+	writeDeployLock(lock)
+
+	*flagEnv = "testnet"
+	require.Equal(t, "MyToken_v2", resolveContractAlias("MyToken"), "alias should resolve to the deployed version")
+	require.Equal(t, "Other", resolveContractAlias("Other"), "names without an alias should be returned as is")
+
+	*flagEnv = LOCAL_ENV_ID
+	require.Equal(t, "MyToken", resolveContractAlias("MyToken"), "aliases should be per environment")
+
+	*flagEnv = "testnet"
+	envContractAliases = map[string]string{"Token": "MyToken", "Registry": "OrbsRegistry"}
+	defer func() { envContractAliases = nil }()
+	require.Equal(t, "MyToken_v2", resolveContractAlias("Token"), "config aliases should be resolved before the lockfile aliases")
+	require.Equal(t, "OrbsRegistry", resolveContractAlias("Registry"))
+}
@@ -0,0 +1,278 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/orbs-network/gamma-cli/jsoncodec"
+	"github.com/orbs-network/orbs-client-sdk-go/codec"
+	"github.com/orbs-network/orbs-client-sdk-go/orbs"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"path"
+	"strconv"
+)
+
+func commandDeployAll(requiredOptions []string) {
+	manifestFile := requiredOptions[0]
+
+	bytes, err := ioutil.ReadFile(manifestFile)
+	if err != nil {
+		die("Could not open manifest file.\n\n%s", err.Error())
+	}
+
+	manifest, err := jsoncodec.UnmarshalDeployManifest(manifestFile, bytes)
+	if err != nil {
+		die("Failed parsing manifest file '%s'.\n\n%s", manifestFile, err.Error())
+	}
+
+	contracts, err := sortManifestContracts(manifest.Contracts)
+	if err != nil {
+		die("Invalid manifest file '%s'.\n\n%s", manifestFile, err.Error())
+	}
+
+	lock := readDeployLock()
+	lockEnv := lock.Env(*flagEnv)
+	client := createOrbsClient()
+
+	deployments := planManifestDeployments(client, lockEnv, contracts)
+	writeDeployLock(lock)
+	if len(deployments) > 0 {
+		requireMainNetConfirmation(client, "deploy the contracts of manifest '"+manifestFile+"'")
+	}
+
+	for _, deployment := range deployments {
+		contract := deployment.contract
+		signer := getManifestContractSigner(contract)
+
+		if deployment.deploy {
+			sourcePath := path.Join(path.Dir(manifestFile), contract.Source)
+			filenames, err := _getSourceFilenames(sourcePath)
+			if err != nil {
+				die("Could not find path of contract '%s'\n\n%s", contract.Name, err.Error())
+			}
+			if len(filenames) == 0 {
+				die("No contract source files found for contract '%s' in '%s'.", contract.Name, sourcePath)
+			}
+			processorType := getProcessorType(contract.Processor, filenames)
+
+			code := getContractCode(sourcePath, filenames, processorType)
+
+			payload, txId, err := client.CreateDeployTransaction(signer.PublicKey, signer.PrivateKey, contract.Name, orbs.ProcessorType(processorType), code...)
+			if err != nil {
+				die("Could not encode payload of the message about to be sent to server.\n\n%s", err.Error())
+			}
+
+			response := sendTransactionAndRequireSuccess(client, payload, txId, "deploy of contract '"+contract.Name+"'")
+			lockEnv.Contracts[contract.Name] = &jsoncodec.DeployLockContract{
+				Name:             contract.Name,
+				SourceHash:       hashSources(code),
+				TxId:             txId,
+				BlockHeight:      strconv.FormatUint(response.BlockHeight, 10),
+				InitCallsPending: len(contract.Init),
+			}
+			writeDeployLock(lock)
+			log("Contract '%s' deployed in block %d (TxId %s).", contract.Name, response.BlockHeight, txId)
+		}
+
+		lockContract := lockEnv.Contracts[contract.Name]
+		for i := deployment.firstInitCall; i < len(contract.Init); i++ {
+			call := contract.Init[i]
+			call.ContractName = getInitCallContractName(contract, call)
+
+			inputArgs, err := jsoncodec.UnmarshalArgs(call.Arguments, getTestKeyFromFile, getAddressFromBook, getArgsOptions())
+			if err != nil {
+				die("Init call %d of contract '%s' has invalid arguments.\n\n%s", i+1, contract.Name, err.Error())
+			}
+
+			payload, txId, err := client.CreateTransaction(signer.PublicKey, signer.PrivateKey, call.ContractName, call.MethodName, inputArgs...)
+			if err != nil {
+				die("Could not encode payload of the message about to be sent to server.\n\n%s", err.Error())
+			}
+
+			sendTransactionAndRequireSuccess(client, payload, txId, "init call '"+call.MethodName+"' of contract '"+contract.Name+"'")
+			lockContract.InitCallsPending = len(contract.Init) - i - 1
+			writeDeployLock(lock)
+			log("  Init call %s.%s succeeded (TxId %s).", call.ContractName, call.MethodName, txId)
+		}
+	}
+
+	log("\nAll %d contracts deployed, lockfile written to '%s'.", len(contracts), *flagDeployLock)
+}
+
+// orders contracts so that every contract comes after the contracts it depends on, keeping manifest order otherwise
+func sortManifestContracts(contracts []*jsoncodec.ManifestContract) ([]*jsoncodec.ManifestContract, error) {
+	byName := make(map[string]*jsoncodec.ManifestContract)
+	for i, contract := range contracts {
+		if contract.Source == "" {
+			return nil, errors.Errorf("contract %d is missing Source", i+1)
+		}
+		if contract.Name == "" {
+			contract.Name = getFilenameWithoutExtension(contract.Source)
+		}
+		if _, found := byName[contract.Name]; found {
+			return nil, errors.Errorf("contract '%s' appears more than once", contract.Name)
+		}
+		byName[contract.Name] = contract
+	}
+
+	var res []*jsoncodec.ManifestContract
+	visited := make(map[string]bool)
+	visiting := make(map[string]bool)
+
+	var visit func(contract *jsoncodec.ManifestContract) error
+	visit = func(contract *jsoncodec.ManifestContract) error {
+		if visited[contract.Name] {
+			return nil
+		}
+		if visiting[contract.Name] {
+			return errors.Errorf("contract '%s' has a circular dependency", contract.Name)
+		}
+		visiting[contract.Name] = true
+		for _, dependency := range contract.DependsOn {
+			dependencyContract, found := byName[dependency]
+			if !found {
+				return errors.Errorf("contract '%s' depends on '%s' which is not in the manifest", contract.Name, dependency)
+			}
+			if err := visit(dependencyContract); err != nil {
+				return err
+			}
+		}
+		visiting[contract.Name] = false
+		visited[contract.Name] = true
+		res = append(res, contract)
+		return nil
+	}
+
+	for _, contract := range contracts {
+		if err := visit(contract); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// a contract of the manifest with what is left to do, its deploy and its init calls from firstInitCall on
+type manifestDeployment struct {
+	contract      *jsoncodec.ManifestContract
+	deploy        bool
+	firstInitCall int
+}
+
+// deployed contracts are skipped unless the lockfile records init calls still pending (eg. after a failed init call),
+// contracts deployed without this lockfile are recorded in it as is, nothing is sent (or confirmed on main net) when all are done
+func planManifestDeployments(client *gammaClient, lockEnv *jsoncodec.DeployLockEnv, contracts []*jsoncodec.ManifestContract) []*manifestDeployment {
+	var res []*manifestDeployment
+	for _, contract := range contracts {
+		if !isContractDeployed(client, getManifestContractSigner(contract), contract.Name) {
+			res = append(res, &manifestDeployment{contract: contract, deploy: true})
+			continue
+		}
+
+		lockContract, found := lockEnv.Contracts[contract.Name]
+		if !found {
+			log("Contract '%s' is already deployed but not in lockfile '%s', recording it and skipping its init calls.", contract.Name, *flagDeployLock)
+			lockEnv.Contracts[contract.Name] = &jsoncodec.DeployLockContract{Name: contract.Name}
+			continue
+		}
+		pending := lockContract.InitCallsPending
+		if pending <= 0 || pending > len(contract.Init) {
+			log("Contract '%s' is already deployed, skipping.", contract.Name)
+			continue
+		}
+		log("Contract '%s' is already deployed, resuming its %d pending init calls.", contract.Name, pending)
+		res = append(res, &manifestDeployment{contract: contract, firstInitCall: len(contract.Init) - pending})
+	}
+	return res
+}
+
+// init calls default to the contract just deployed, other contracts are named by their alias like in send-tx
+func getInitCallContractName(contract *jsoncodec.ManifestContract, call *jsoncodec.SendTx) string {
+	if call.ContractName == "" {
+		return contract.Name
+	}
+	return resolveContractAlias(call.ContractName)
+}
+
+func getManifestContractSigner(contract *jsoncodec.ManifestContract) *jsoncodec.RawKey {
+	if contract.Signer == "" {
+		return getTestKeyFromFile(*flagSigner)
+	}
+	return getTestKeyFromFile(contract.Signer)
+}
+
+func isContractDeployed(client *gammaClient, signer *jsoncodec.RawKey, contractName string) bool {
+	payload, err := client.CreateQuery(signer.PublicKey, DEPLOY_SYSTEM_CONTRACT_NAME, DEPLOY_GET_INFO_SYSTEM_METHOD_NAME, contractName)
+	if err != nil {
+		die("Could not encode payload of the message about to be sent to server.\n\n%s", err.Error())
+	}
+
+	response, clientErr := client.SendQuery(payload)
+	handleNoConnectionGracefully(clientErr, client)
+	if response == nil {
+		die("Request run-query failed on server.\n\n%s", clientErr.Error())
+	}
+
+	return response.ExecutionResult == codec.EXECUTION_RESULT_SUCCESS
+}
+
+func sendTransactionAndRequireSuccess(client *gammaClient, payload []byte, txId string, description string) *codec.SendTransactionResponse {
+	response, clientErr := client.SendTransaction(payload, txId)
+	handleNoConnectionGracefully(clientErr, client)
+	if response == nil {
+		die("Request %s failed on server.\n\n%s", description, clientErr.Error())
+	}
+
+	if response.TransactionStatus != codec.TRANSACTION_STATUS_COMMITTED || response.ExecutionResult != codec.EXECUTION_RESULT_SUCCESS {
+		output, err := jsoncodec.MarshalSendTxResponse(response, txId, getArgsOptions())
+		if err != nil {
+			die("Could not encode send-tx response to json.\n\n%s", err.Error())
+		}
+		die("Request %s did not succeed.\n\n%s", description, string(output))
+	}
+
+	return response
+}
+
+func hashSources(code [][]byte) string {
+	hash := sha256.New()
+	for _, source := range code {
+		hash.Write(source)
+	}
+	return "0x" + hex.EncodeToString(hash.Sum(nil))
+}
+
+func readDeployLock() *jsoncodec.DeployLock {
+	if !doesFileExist(*flagDeployLock) {
+		return &jsoncodec.DeployLock{}
+	}
+
+	bytes, err := ioutil.ReadFile(*flagDeployLock)
+	if err != nil {
+		die("Could not open deployment lockfile '%s'.\n\n%s", *flagDeployLock, err.Error())
+	}
+
+	lock, err := jsoncodec.UnmarshalDeployLock(bytes)
+	if err != nil || lock == nil {
+		die("Failed parsing deployment lockfile '%s'.\n\n%v", *flagDeployLock, err)
+	}
+
+	return lock
+}
+
+func writeDeployLock(lock *jsoncodec.DeployLock) {
+	bytes, err := jsoncodec.MarshalDeployLock(lock)
+	if err != nil {
+		die("Could not encode deployment lockfile to json.\n\n%s", err.Error())
+	}
+
+	err = ioutil.WriteFile(*flagDeployLock, bytes, 0644)
+	if err != nil {
+		die("Could not write deployment lockfile '%s'.\n\n%s", *flagDeployLock, err.Error())
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"github.com/orbs-network/gamma-cli/jsoncodec"
+	"github.com/orbs-network/orbs-client-sdk-go/codec"
+	"github.com/orbs-network/orbs-client-sdk-go/orbs"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/protocol/client"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestSortManifestContracts(t *testing.T) {
+	contracts := []*jsoncodec.ManifestContract{
+		{Name: "Exchange", Source: "exchange", DependsOn: []string{"Token", "Oracle"}},
+		{Source: "contracts/Token.go"},
+		{Name: "Oracle", Source: "oracle.go", DependsOn: []string{"Token"}},
+		{Name: "Standalone", Source: "standalone.go"},
+	}
+
+	sorted, err := sortManifestContracts(contracts)
+	require.NoError(t, err, "sort should not fail")
+
+	var names []string
+	for _, contract := range sorted {
+		names = append(names, contract.Name)
+	}
+	require.Equal(t, []string{"Token", "Oracle", "Exchange", "Standalone"}, names, "contracts should be sorted by dependency order")
+}
+
+func TestSortManifestContracts_Invalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		contracts []*jsoncodec.ManifestContract
+	}{
+		{"MissingSource", []*jsoncodec.ManifestContract{{Name: "Token"}}},
+		{"Duplicate", []*jsoncodec.ManifestContract{{Name: "Token", Source: "a.go"}, {Name: "Token", Source: "b.go"}}},
+		{"UnknownDependency", []*jsoncodec.ManifestContract{{Name: "Token", Source: "a.go", DependsOn: []string{"Oracle"}}}},
+		{"Circular", []*jsoncodec.ManifestContract{{Name: "A", Source: "a.go", DependsOn: []string{"B"}}, {Name: "B", Source: "b.go", DependsOn: []string{"A"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := sortManifestContracts(tt.contracts)
+			require.Error(t, err, "sort should fail")
+		})
+	}
+}
+
+func TestCompareDeployedCode(t *testing.T) {
+	local := [][]byte{[]byte("package main // a"), []byte("package main // b")}
+
+	require.Equal(t, DEPLOY_CHECK_IDENTICAL, compareDeployedCode(local, [][]byte{[]byte("package main // a"), []byte("package main // b")}))
+	require.Equal(t, DEPLOY_CHECK_DIFFERENT, compareDeployedCode(local, [][]byte{[]byte("package main // a"), []byte("package main // c")}))
+	require.Equal(t, DEPLOY_CHECK_DIFFERENT, compareDeployedCode(local, [][]byte{[]byte("package main // a")}))
+}
+
+func TestCompareDeployedWholeCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		local    [][]byte
+		deployed string
+		expected string
+	}{
+		{"SingleFileIdentical", [][]byte{[]byte("package main // a")}, "package main // a", DEPLOY_CHECK_IDENTICAL},
+		{"SingleFileDifferent", [][]byte{[]byte("package main // a")}, "package main // b", DEPLOY_CHECK_DIFFERENT},
+		{"SeveralFiles", [][]byte{[]byte("package main // a"), []byte("package main // b")}, "package main // a", DEPLOY_CHECK_UNKNOWN_PARTS},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, compareDeployedWholeCode(tt.local, []byte(tt.deployed)))
+		})
+	}
+}
+
+func TestPlanManifestDeployments_AllDeployed(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		response := (&client.RunQueryResponseBuilder{
+			RequestResult: &client.RequestResultBuilder{RequestStatus: protocol.REQUEST_STATUS_COMPLETED},
+			QueryResult:   &protocol.QueryResultBuilder{ExecutionResult: protocol.EXECUTION_RESULT_SUCCESS},
+		}).Build()
+		w.Header().Set("Content-Type", orbs.CONTENT_TYPE_MEMBUFFERS)
+		w.Write(response.Raw())
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "gamma-cli-deploy-all")
+	require.NoError(t, err, "temp dir should be created")
+	defer os.RemoveAll(dir)
+	prevKeys := *flagKeyFile
+	defer func() { *flagKeyFile, mainNetConfirmed = prevKeys, false }()
+	*flagKeyFile = path.Join(dir, TEST_KEYS_FILENAME)
+	mainNetConfirmed = false
+
+	gamma := newGammaClient([]string{server.URL}, 42, codec.NETWORK_TYPE_MAIN_NET, false, nil)
+	mint := &jsoncodec.SendTx{MethodName: "mint"}
+	approve := &jsoncodec.SendTx{MethodName: "approve"}
+	contracts := []*jsoncodec.ManifestContract{
+		{Name: "Token", Source: "token", Init: []*jsoncodec.SendTx{mint, approve}},
+		{Name: "Exchange", Source: "exchange", Signer: "user2", Init: []*jsoncodec.SendTx{mint}},
+		{Name: "Oracle", Source: "oracle", Init: []*jsoncodec.SendTx{mint}},
+	}
+
+	lockEnv := (&jsoncodec.DeployLock{}).Env("test")
+	lockEnv.Contracts["Token"] = &jsoncodec.DeployLockContract{Name: "Token", InitCallsPending: 1}
+	lockEnv.Contracts["Oracle"] = &jsoncodec.DeployLockContract{Name: "Oracle"}
+
+	var deployments []*manifestDeployment
+	captureStdout(t, func() { deployments = planManifestDeployments(gamma, lockEnv, contracts) })
+	require.Equal(t, []*manifestDeployment{{contract: contracts[0], firstInitCall: 1}}, deployments, "only the pending init call of Token should be resumed")
+	require.Equal(t, &jsoncodec.DeployLockContract{Name: "Exchange"}, lockEnv.Contracts["Exchange"], "deployed contracts missing from the lockfile should be recorded")
+	require.Equal(t, []string{orbs.CALL_METHOD_URL, orbs.CALL_METHOD_URL, orbs.CALL_METHOD_URL}, requests, "only deployment queries should be sent")
+	require.False(t, mainNetConfirmed, "planning should not ask for main net confirmation")
+}
+
+func TestGetInitCallContractName(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gamma-cli-lock")
+	require.NoError(t, err, "temp dir should be created")
+	defer os.RemoveAll(dir)
+
+	prevLock, prevEnv := *flagDeployLock, *flagEnv
+	defer func() { *flagDeployLock, *flagEnv = prevLock, prevEnv }()
+	*flagDeployLock = path.Join(dir, DEPLOY_LOCK_FILENAME)
+	*flagEnv = "testnet"
+
+	lock := &jsoncodec.DeployLock{}
+	lock.Env("testnet").Aliases["Registry"] = versionedContractName("Registry", 3)
+	writeDeployLock(lock)
+
+	contract := &jsoncodec.ManifestContract{Name: "Token"}
+	require.Equal(t, "Token", getInitCallContractName(contract, &jsoncodec.SendTx{MethodName: "mint"}), "init calls should default to the deployed contract")
+	require.Equal(t, "Registry_v3", getInitCallContractName(contract, &jsoncodec.SendTx{ContractName: "Registry", MethodName: "register"}), "other contracts should be resolved through their alias")
+}
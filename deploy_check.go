@@ -0,0 +1,116 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"bytes"
+	"github.com/orbs-network/gamma-cli/jsoncodec"
+	"github.com/orbs-network/orbs-client-sdk-go/codec"
+	"os"
+)
+
+const DEPLOY_GET_CODE_PARTS_SYSTEM_METHOD_NAME = "getCodeParts"
+const DEPLOY_GET_CODE_PART_SYSTEM_METHOD_NAME = "getCodePart"
+const DEPLOY_GET_CODE_SYSTEM_METHOD_NAME = "getCode"
+
+const DEPLOY_CHECK_IDENTICAL = "identical"
+const DEPLOY_CHECK_DIFFERENT = "different"
+const DEPLOY_CHECK_MISSING = "missing"
+const DEPLOY_CHECK_UNKNOWN = "deployed (server does not expose contract code)"
+const DEPLOY_CHECK_UNKNOWN_PARTS = "deployed (server does not expose the code of each source file to compare with)"
+
+// prints whether the deployed contract matches the local sources, exits nonzero unless identical
+func commandDeployCheck(client *gammaClient, signer *jsoncodec.RawKey, contractName string, code [][]byte) {
+	status := checkDeployedContract(client, signer, contractName, code)
+
+	log("Contract '%s' on environment '%s' is %s.", contractName, *flagEnv, status)
+	log("Local source hash: %s", hashSources(code))
+
+	if status != DEPLOY_CHECK_IDENTICAL {
+		os.Exit(1)
+	}
+	exit()
+}
+
+func checkDeployedContract(client *gammaClient, signer *jsoncodec.RawKey, contractName string, code [][]byte) string {
+	if !isContractDeployed(client, signer, contractName) {
+		return DEPLOY_CHECK_MISSING
+	}
+
+	if deployedParts, found := getDeployedCodeParts(client, signer, contractName); found {
+		return compareDeployedCode(code, deployedParts)
+	}
+	if deployedCode, found := getDeployedWholeCode(client, signer, contractName); found {
+		return compareDeployedWholeCode(code, deployedCode)
+	}
+	return DEPLOY_CHECK_UNKNOWN
+}
+
+func compareDeployedCode(localCode [][]byte, deployedCode [][]byte) string {
+	if len(localCode) != len(deployedCode) {
+		return DEPLOY_CHECK_DIFFERENT
+	}
+	for i := range localCode {
+		if !bytes.Equal(localCode[i], deployedCode[i]) {
+			return DEPLOY_CHECK_DIFFERENT
+		}
+	}
+	return DEPLOY_CHECK_IDENTICAL
+}
+
+// older servers only expose getCode, which cannot be compared part by part with a contract of several source files
+func compareDeployedWholeCode(localCode [][]byte, deployedCode []byte) string {
+	if len(localCode) != 1 {
+		return DEPLOY_CHECK_UNKNOWN_PARTS
+	}
+	return compareDeployedCode(localCode, [][]byte{deployedCode})
+}
+
+// newer servers expose the code as parts (one per source file)
+func getDeployedCodeParts(client *gammaClient, signer *jsoncodec.RawKey, contractName string) ([][]byte, bool) {
+	if outputs, ok := queryDeploymentsContract(client, signer, DEPLOY_GET_CODE_PARTS_SYSTEM_METHOD_NAME, contractName); ok && len(outputs) == 1 {
+		if count, isUint32 := outputs[0].(uint32); isUint32 {
+			var res [][]byte
+			for i := uint32(0); i < count; i++ {
+				part, ok := queryDeploymentsContract(client, signer, DEPLOY_GET_CODE_PART_SYSTEM_METHOD_NAME, contractName, i)
+				if !ok || len(part) != 1 {
+					return nil, false
+				}
+				partBytes, isBytes := part[0].([]byte)
+				if !isBytes {
+					return nil, false
+				}
+				res = append(res, partBytes)
+			}
+			return res, true
+		}
+	}
+	return nil, false
+}
+
+func getDeployedWholeCode(client *gammaClient, signer *jsoncodec.RawKey, contractName string) ([]byte, bool) {
+	if outputs, ok := queryDeploymentsContract(client, signer, DEPLOY_GET_CODE_SYSTEM_METHOD_NAME, contractName); ok && len(outputs) == 1 {
+		if code, isBytes := outputs[0].([]byte); isBytes {
+			return code, true
+		}
+	}
+	return nil, false
+}
+
+func queryDeploymentsContract(client *gammaClient, signer *jsoncodec.RawKey, methodName string, args ...interface{}) ([]interface{}, bool) {
+	payload, err := client.CreateQuery(signer.PublicKey, DEPLOY_SYSTEM_CONTRACT_NAME, methodName, args...)
+	if err != nil {
+		die("Could not encode payload of the message about to be sent to server.\n\n%s", err.Error())
+	}
+
+	response, clientErr := client.SendQuery(payload)
+	handleNoConnectionGracefully(clientErr, client)
+	if response == nil || response.ExecutionResult != codec.EXECUTION_RESULT_SUCCESS {
+		return nil, false
+	}
+	return response.OutputArguments, true
+}
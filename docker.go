@@ -10,9 +10,6 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
-	"github.com/pkg/errors"
-	"io/ioutil"
-	"net/http"
 	"os"
 	"os/exec"
 	"regexp"
@@ -25,6 +22,16 @@ const DOCKER_TAG_NOT_FOUND = "not found"
 const DOCKER_TAG_EXPERIMENTAL = "experimental"
 
 func commandStartLocal(requiredOptions []string) {
+	override := getGammaConfigOverride()
+	overrideJson, err := override.Marshal()
+	if err != nil {
+		die("Could not encode -override-config.\n\n%s", err.Error())
+	}
+	*flagOverrideConfig = string(overrideJson)
+	if !isDockerContainerRunning(gammaHandlerOptions().containerName) {
+		logGammaConfig(override)
+	}
+
 	commandStartLocalContainer(gammaHandlerOptions(), requiredOptions)
 
 	if prismEnabled() {
@@ -56,7 +63,7 @@ func commandUpgrade(requiredOptions []string) {
 }
 
 func commandStartLocalContainer(dockerOptions handlerOptions, requiredOptions []string) {
-	version := verifyDockerInstalled(dockerOptions, dockerOptions.dockerRegistryTagsUrl)
+	version := verifyDockerInstalled(dockerOptions)
 
 	if !doesFileExist(*flagKeyFile) {
 		commandGenerateTestKeys(nil)
@@ -117,7 +124,7 @@ func commandStartLocalContainer(dockerOptions handlerOptions, requiredOptions []
 }
 
 func commandStopLocalContainer(dockerOptions handlerOptions, requiredOptions []string) {
-	verifyDockerInstalled(dockerOptions, dockerOptions.dockerRegistryTagsUrl)
+	verifyDockerInstalled(dockerOptions)
 
 	out, err := exec.Command("docker", "stop", dockerOptions.containerName).CombinedOutput()
 	if err != nil {
@@ -145,8 +152,12 @@ func commandStopLocalContainer(dockerOptions handlerOptions, requiredOptions []s
 }
 
 func commandUpgradeImage(dockerOptions handlerOptions, requiredOptions []string) bool {
-	currentTag := verifyDockerInstalled(dockerOptions, dockerOptions.dockerRegistryTagsUrl)
-	latestTag := getLatestDockerTag(dockerOptions.dockerRegistryTagsUrl)
+	currentTag := verifyDockerInstalled(dockerOptions)
+	if dockerOptions.dockerTag != "" {
+		log("Current %s version is pinned to %s and does not upgrade.", dockerOptions.name, currentTag)
+		return false
+	}
+	latestTag := getLatestDockerTag(dockerOptions.dockerRegistry)
 
 	if !isExperimental() && cmpTags(latestTag, currentTag) <= 0 {
 		log("Current %s stable version %s does not require upgrade.", dockerOptions.name, currentTag)
@@ -164,7 +175,7 @@ func commandUpgradeImage(dockerOptions handlerOptions, requiredOptions []string)
 
 func showLogs(requiredOptions []string) {
 	dockerOptions := gammaHandlerOptions()
-	verifyDockerInstalled(dockerOptions, dockerOptions.dockerRegistryTagsUrl)
+	verifyDockerInstalled(dockerOptions)
 
 	cmd := exec.Command("docker", "logs", "-f", "--tail=20", dockerOptions.containerName)
 	stdout, err := cmd.StderrPipe() // println() and print() go to stderr
@@ -185,8 +196,7 @@ func showLogs(requiredOptions []string) {
 	}
 }
 
-// TODO remove dockerRegistryUrl as separate parameter
-func verifyDockerInstalled(dockerOptions handlerOptions, dockerRegistryTagUrl string) string {
+func verifyDockerInstalled(dockerOptions handlerOptions) string {
 	out, err := exec.Command("docker", "images", dockerOptions.dockerRepo).CombinedOutput()
 	if err != nil {
 		if runtime.GOOS == "darwin" {
@@ -196,25 +206,29 @@ func verifyDockerInstalled(dockerOptions handlerOptions, dockerRegistryTagUrl st
 		}
 	}
 
-	existingTag := extractTagFromDockerImagesOutput(dockerOptions.dockerRepo, string(out))
+	existingTag := extractTagFromDockerImagesOutput(dockerOptions.dockerRepo, dockerOptions.dockerTag, string(out))
 	if existingTag != DOCKER_TAG_NOT_FOUND {
 		return existingTag
 	}
 
-	latestTag := getLatestDockerTag(dockerRegistryTagUrl)
+	tag := dockerOptions.dockerTag
+	if tag == "" {
+		tag = getLatestDockerTag(dockerOptions.dockerRegistry)
+	}
 
-	log("%s image is not installed, downloading version %s:\n", dockerOptions.name, latestTag)
-	cmd := exec.Command("docker", "pull", fmt.Sprintf("%s:%s", dockerOptions.dockerRepo, latestTag))
+	log("%s image is not installed, downloading version %s:\n", dockerOptions.name, tag)
+	cmd := exec.Command("docker", "pull", fmt.Sprintf("%s:%s", dockerOptions.dockerRepo, tag))
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Run()
 	log("")
 
 	out, err = exec.Command("docker", "images", dockerOptions.dockerRepo).CombinedOutput()
-	if err != nil || strings.Count(string(out), "\n") == 1 {
-		die("Could not download docker image.")
+	existingTag = extractTagFromDockerImagesOutput(dockerOptions.dockerRepo, dockerOptions.dockerTag, string(out))
+	if err != nil || existingTag == DOCKER_TAG_NOT_FOUND {
+		die("Could not download docker image %s:%s, private registries require 'docker login' first.", dockerOptions.dockerRepo, tag)
 	}
-	return extractTagFromDockerImagesOutput(dockerOptions.dockerRepo, string(out))
+	return existingTag
 }
 
 func isDockerContainerRunning(containerName string) bool {
@@ -225,9 +239,12 @@ func isDockerContainerRunning(containerName string) bool {
 	return strings.Count(string(out), "\n") > 1
 }
 
-func extractTagFromDockerImagesOutput(dockerRepo string, out string) string {
+// a pinned tag is matched exactly, otherwise the first version tag (or the experimental tag)
+func extractTagFromDockerImagesOutput(dockerRepo string, tag string, out string) string {
 	pattern := fmt.Sprintf(`%s\s+(v\S+)`, regexp.QuoteMeta(dockerRepo))
-	if isExperimental() {
+	if tag != "" {
+		pattern = fmt.Sprintf(`(?m)^%s\s+(%s)\s`, regexp.QuoteMeta(dockerRepo), regexp.QuoteMeta(tag))
+	} else if isExperimental() {
 		pattern = fmt.Sprintf(`%s\s+(%s)`, regexp.QuoteMeta(dockerRepo), regexp.QuoteMeta(DOCKER_TAG_EXPERIMENTAL))
 	}
 	re := regexp.MustCompile(pattern)
@@ -238,22 +255,17 @@ func extractTagFromDockerImagesOutput(dockerRepo string, out string) string {
 	return res[1]
 }
 
-func getLatestDockerTag(dockerRegistryTagsUrl string) string {
+func getLatestDockerTag(registry *dockerRegistry) string {
 	if isExperimental() {
 		return DOCKER_TAG_EXPERIMENTAL
 	}
-	resp, err := http.Get(dockerRegistryTagsUrl)
+	bytes, err := registry.getTags()
 	if err != nil {
-		die("Cannot connect to docker registry to get image list.")
-	}
-	defer resp.Body.Close()
-	bytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil || len(bytes) == 0 {
-		die("Bad image list response from docker registry.")
+		die("Cannot get image list from docker registry.\n\n%s", err.Error())
 	}
-	tag, err := extractLatestTagFromDockerHubResponse(bytes)
+	tag, err := registry.extractLatestTag(bytes)
 	if err != nil {
-		die("Cannot parse image list response from docker registry.")
+		die("Cannot find a version tag in image list response from docker registry.")
 	}
 	return tag
 }
@@ -270,16 +282,11 @@ func extractLatestTagFromDockerHubResponse(responseBytes []byte) (string, error)
 	if err != nil {
 		return "", err
 	}
-	maxTag := ""
+	var tags []string
 	for _, result := range response.Results {
-		if cmpTags(result.Name, maxTag) > 0 {
-			maxTag = result.Name
-		}
-	}
-	if maxTag == "" {
-		return "", errors.New("no valid tags found")
+		tags = append(tags, result.Name)
 	}
-	return maxTag, nil
+	return extractLatestTag(tags)
 }
 
 func isExperimental() bool {
@@ -0,0 +1,249 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/orbs-network/gamma-cli/jsoncodec"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	GAMMA_DOCKER_REPO = "orbsnetwork/gamma"
+	PRISM_DOCKER_REPO = "orbsnetwork/prism"
+
+	DOCKER_HUB_TAGS_URL      = "https://registry.hub.docker.com/v2/repositories/%s/tags/"
+	DOCKER_HUB_REGISTRY_URL  = "https://registry-1.docker.io"
+	DOCKER_REGISTRY_TAGS_URL = "%s/v2/%s/tags/list"
+)
+
+var dockerHubHosts = []string{"docker.io", "index.docker.io", "registry-1.docker.io"}
+var bearerChallengeParamPattern = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// not http.DefaultClient which carries the Http settings of the environment for its endpoints
+var dockerRegistryHttpClient = &http.Client{}
+
+// bearer tokens by the url they were issued for, reused for the rest of the run
+var dockerRegistryTokens = make(map[string]string)
+
+type dockerImage struct {
+	repo     string
+	tag      string // pinned, the latest one in the registry if empty
+	registry *dockerRegistry
+}
+
+type dockerRegistry struct {
+	tagsUrl     string
+	isDockerHub bool // the Docker Hub api lists tags as results, the Docker Registry v2 api as tags
+	username    string
+	password    string
+}
+
+var envGammaImage *jsoncodec.ConfImage // GammaImage of the environment
+var envPrismImage *jsoncodec.ConfImage // PrismImage of the environment
+
+func getDockerImage(field string, defaultRepo string, conf *jsoncodec.ConfImage, pinnedTag string) *dockerImage {
+	image, err := newDockerImage(defaultRepo, conf, pinnedTag)
+	if err != nil {
+		die("Invalid %s of environment '%s' in '%s'.\n\n%s", field, *flagEnv, getConfigSource(field), err.Error())
+	}
+	return image
+}
+
+// the official image on Docker Hub unless the environment configures another one, a pinned tag (eg. -server-version) overrides its Tag
+func newDockerImage(defaultRepo string, conf *jsoncodec.ConfImage, pinnedTag string) (*dockerImage, error) {
+	res := &dockerImage{repo: defaultRepo, tag: pinnedTag}
+	if conf == nil {
+		conf = &jsoncodec.ConfImage{}
+	}
+	if conf.Repository != "" {
+		res.repo = conf.Repository
+	}
+	if res.tag == "" {
+		res.tag = conf.Tag
+	}
+
+	host, name := splitDockerRepo(res.repo)
+	if name == "" || strings.ContainsAny(name[strings.LastIndex(name, "/")+1:], ":@") {
+		return nil, errors.Errorf("Repository should be an image repository without a tag (eg. registry.example.com/team/gamma), current value: '%s'", res.repo)
+	}
+	registry, err := newDockerRegistry(host, name, conf)
+	if err != nil {
+		return nil, err
+	}
+	res.registry = registry
+	return res, nil
+}
+
+// tags are listed from Docker Hub for its public images, otherwise from the Docker Registry v2 api of the registry
+func newDockerRegistry(host string, name string, conf *jsoncodec.ConfImage) (*dockerRegistry, error) {
+	res := &dockerRegistry{
+		username: os.ExpandEnv(conf.Username),
+		password: os.ExpandEnv(conf.Password),
+	}
+	isDockerHub := host == ""
+	for _, hubHost := range dockerHubHosts {
+		isDockerHub = isDockerHub || host == hubHost
+	}
+	if isDockerHub && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+
+	switch {
+	case conf.Registry != "":
+		u, err := url.Parse(conf.Registry)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, errors.Errorf("Registry should be an http(s) url (eg. https://registry.example.com), current value: '%s'", conf.Registry)
+		}
+		res.tagsUrl = fmt.Sprintf(DOCKER_REGISTRY_TAGS_URL, strings.TrimSuffix(conf.Registry, "/"), name)
+	case isDockerHub && res.username == "":
+		res.tagsUrl = fmt.Sprintf(DOCKER_HUB_TAGS_URL, name)
+		res.isDockerHub = true
+	case isDockerHub:
+		// private Docker Hub repositories are listed through the registry api which accepts the credentials
+		res.tagsUrl = fmt.Sprintf(DOCKER_REGISTRY_TAGS_URL, DOCKER_HUB_REGISTRY_URL, name)
+	default:
+		res.tagsUrl = fmt.Sprintf(DOCKER_REGISTRY_TAGS_URL, "https://"+host, name)
+	}
+	return res, nil
+}
+
+// the first component is a registry host if it looks like one, like docker itself decides
+func splitDockerRepo(repo string) (string, string) {
+	i := strings.Index(repo, "/")
+	if i == -1 {
+		return "", repo
+	}
+	first := repo[:i]
+	if first == "localhost" || strings.ContainsAny(first, ".:") {
+		return first, repo[i+1:]
+	}
+	return "", repo
+}
+
+func (r *dockerRegistry) getTags() ([]byte, error) {
+	return r.get(r.tagsUrl)
+}
+
+// the Docker Hub api lists tags as results, the Docker Registry v2 api as tags
+func (r *dockerRegistry) extractLatestTag(responseBytes []byte) (string, error) {
+	if r.isDockerHub {
+		return extractLatestTagFromDockerHubResponse(responseBytes)
+	}
+
+	var response struct {
+		Tags []string
+	}
+	if err := json.Unmarshal(responseBytes, &response); err != nil {
+		return "", err
+	}
+	return extractLatestTag(response.Tags)
+}
+
+// registries either accept basic auth or answer 401 with a bearer challenge naming the token server
+// a token is fetched once per run, again only when the registry rejects it (eg. expired)
+func (r *dockerRegistry) get(requestUrl string) ([]byte, error) {
+	resp, err := r.do(requestUrl, dockerRegistryTokens[requestUrl])
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && strings.HasPrefix(resp.Header.Get("Www-Authenticate"), "Bearer ") {
+		resp.Body.Close()
+		token, err := r.fetchToken(resp.Header.Get("Www-Authenticate"))
+		if err != nil {
+			return nil, err
+		}
+		dockerRegistryTokens[requestUrl] = token
+		if resp, err = r.do(requestUrl, token); err != nil {
+			return nil, err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return nil, errors.Errorf("registry denied access to %s (%s), check the Username and Password of the image", requestUrl, resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("registry responded to %s with %s", requestUrl, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+func (r *dockerRegistry) do(requestUrl string, token string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", requestUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if r.username != "" {
+		req.SetBasicAuth(r.username, r.password)
+	}
+	return dockerRegistryHttpClient.Do(req)
+}
+
+func (r *dockerRegistry) fetchToken(challenge string) (string, error) {
+	params := make(map[string]string)
+	for _, m := range bearerChallengeParamPattern.FindAllStringSubmatch(challenge, -1) {
+		params[m[1]] = m[2]
+	}
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", errors.Errorf("registry sent an invalid authentication challenge: %s", challenge)
+	}
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	resp, err := r.do(realm.String(), "")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("registry token server %s responded with %s, check the Username and Password of the image", realm.Host, resp.Status)
+	}
+
+	var response struct {
+		Token       string
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", errors.Wrap(err, "bad response from registry token server")
+	}
+	if response.Token != "" {
+		return response.Token, nil
+	}
+	if response.AccessToken != "" {
+		return response.AccessToken, nil
+	}
+	return "", errors.New("registry token server did not return a token")
+}
+
+func extractLatestTag(tags []string) (string, error) {
+	maxTag := ""
+	for _, tag := range tags {
+		if cmpTags(tag, maxTag) > 0 {
+			maxTag = tag
+		}
+	}
+	if maxTag == "" {
+		return "", errors.New("no valid tags found")
+	}
+	return maxTag, nil
+}
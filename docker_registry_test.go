@@ -0,0 +1,143 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"fmt"
+	"github.com/orbs-network/gamma-cli/jsoncodec"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestNewDockerImage(t *testing.T) {
+	os.Setenv("GAMMA_TEST_REGISTRY_PASSWORD", "secret")
+	defer os.Unsetenv("GAMMA_TEST_REGISTRY_PASSWORD")
+
+	tests := []struct {
+		name             string
+		conf             *jsoncodec.ConfImage
+		pinnedTag        string
+		expectedRepo     string
+		expectedTag      string
+		expectedTagsUrl  string
+		expectedHub      bool
+		expectedPassword string
+		expectErr        bool
+	}{
+		{"Default", nil, "", "orbsnetwork/gamma", "", "https://registry.hub.docker.com/v2/repositories/orbsnetwork/gamma/tags/", true, "", false},
+		{"PinnedTag", nil, "v1.3.0", "orbsnetwork/gamma", "v1.3.0", "https://registry.hub.docker.com/v2/repositories/orbsnetwork/gamma/tags/", true, "", false},
+		{"ConfigTag", &jsoncodec.ConfImage{Tag: "v1.2.0"}, "", "orbsnetwork/gamma", "v1.2.0", "https://registry.hub.docker.com/v2/repositories/orbsnetwork/gamma/tags/", true, "", false},
+		{"PinnedTagOverridesConfigTag", &jsoncodec.ConfImage{Tag: "v1.2.0"}, "v1.3.0", "orbsnetwork/gamma", "v1.3.0", "https://registry.hub.docker.com/v2/repositories/orbsnetwork/gamma/tags/", true, "", false},
+		{"DockerHubOfficialImage", &jsoncodec.ConfImage{Repository: "gamma"}, "", "gamma", "", "https://registry.hub.docker.com/v2/repositories/library/gamma/tags/", true, "", false},
+		{"DockerHubWithCredentials", &jsoncodec.ConfImage{Repository: "myteam/gamma", Username: "ci", Password: "${GAMMA_TEST_REGISTRY_PASSWORD}"}, "", "myteam/gamma", "", "https://registry-1.docker.io/v2/myteam/gamma/tags/list", false, "secret", false},
+		{"PrivateRegistry", &jsoncodec.ConfImage{Repository: "registry.example.com/team/gamma"}, "", "registry.example.com/team/gamma", "", "https://registry.example.com/v2/team/gamma/tags/list", false, "", false},
+		{"PrivateRegistryWithPort", &jsoncodec.ConfImage{Repository: "localhost:5000/gamma"}, "", "localhost:5000/gamma", "", "https://localhost:5000/v2/gamma/tags/list", false, "", false},
+		{"ExplicitRegistry", &jsoncodec.ConfImage{Repository: "localhost:5000/gamma", Registry: "http://localhost:5000/"}, "", "localhost:5000/gamma", "", "http://localhost:5000/v2/gamma/tags/list", false, "", false},
+		{"RepositoryWithTag", &jsoncodec.ConfImage{Repository: "registry.example.com:5000/gamma:v1.3.0"}, "", "", "", "", false, "", true},
+		{"InvalidRegistry", &jsoncodec.ConfImage{Registry: "registry.example.com"}, "", "", "", "", false, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			image, err := newDockerImage(GAMMA_DOCKER_REPO, tt.conf, tt.pinnedTag)
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedRepo, image.repo)
+			require.Equal(t, tt.expectedTag, image.tag)
+			require.Equal(t, tt.expectedTagsUrl, image.registry.tagsUrl)
+			require.Equal(t, tt.expectedHub, image.registry.isDockerHub)
+			require.Equal(t, tt.expectedPassword, image.registry.password)
+		})
+	}
+}
+
+func TestDockerRegistryListTags(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			if username, password, _ := r.BasicAuth(); username != "ci" || password != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			require.Equal(t, "repository:team/gamma:pull", r.URL.Query().Get("scope"))
+			fmt.Fprint(w, `{"token": "registry-token"}`)
+		case "/v2/team/gamma/tags/list":
+			if r.Header.Get("Authorization") != "Bearer registry-token" {
+				w.Header().Set("Www-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:team/gamma:pull"`, server.URL))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"name": "team/gamma", "tags": ["v1.2.0", "latest", "v1.10.1", "v1.3.0"]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		conf      *jsoncodec.ConfImage
+		expected  string
+		expectErr bool
+	}{
+		{"TokenAuth", &jsoncodec.ConfImage{Repository: "registry.example.com/team/gamma", Registry: server.URL, Username: "ci", Password: "secret"}, "v1.10.1", false},
+		{"WrongCredentials", &jsoncodec.ConfImage{Repository: "registry.example.com/team/gamma", Registry: server.URL, Username: "ci", Password: "wrong"}, "", true},
+		{"UnknownRepository", &jsoncodec.ConfImage{Repository: "registry.example.com/team/prism", Registry: server.URL}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dockerRegistryTokens = make(map[string]string)
+			image, err := newDockerImage(GAMMA_DOCKER_REPO, tt.conf, "")
+			require.NoError(t, err)
+
+			bytes, err := image.registry.getTags()
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			tag, err := image.registry.extractLatestTag(bytes)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, tag)
+		})
+	}
+}
+
+func TestDockerRegistryReusesToken(t *testing.T) {
+	var server *httptest.Server
+	tokenRequests := 0
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			tokenRequests++
+			fmt.Fprint(w, `{"token": "registry-token"}`)
+		case "/v2/team/gamma/tags/list":
+			if r.Header.Get("Authorization") != "Bearer registry-token" {
+				w.Header().Set("Www-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:team/gamma:pull"`, server.URL))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"name": "team/gamma", "tags": ["v1.2.0"]}`)
+		}
+	}))
+	defer server.Close()
+
+	dockerRegistryTokens = make(map[string]string)
+	for i := 0; i < 2; i++ {
+		image, err := newDockerImage(GAMMA_DOCKER_REPO, &jsoncodec.ConfImage{Repository: "registry.example.com/team/gamma", Registry: server.URL}, "")
+		require.NoError(t, err)
+		_, err = image.registry.getTags()
+		require.NoError(t, err)
+	}
+	require.Equal(t, 1, tokenRequests, "token should be fetched once per run")
+}
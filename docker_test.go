@@ -154,3 +154,28 @@ func TestCmpTags(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractTagFromDockerImagesOutput(t *testing.T) {
+	out := `REPOSITORY                        TAG                 IMAGE ID            CREATED             SIZE
+registry.example.com/team/gamma   my-build            4a3c2f1e0b9d        2 hours ago         130MB
+orbsnetwork/gamma                 v1.3.0              0f9e8d7c6b5a        2 weeks ago         126MB
+orbsnetwork/gamma                 v1.2.0              1a2b3c4d5e6f        2 months ago        126MB
+`
+	tests := []struct {
+		name     string
+		repo     string
+		tag      string
+		expected string
+	}{
+		{"Latest", "orbsnetwork/gamma", "", "v1.3.0"},
+		{"Pinned", "orbsnetwork/gamma", "v1.2.0", "v1.2.0"},
+		{"PinnedMissing", "orbsnetwork/gamma", "v1.1.0", DOCKER_TAG_NOT_FOUND},
+		{"PinnedNonSemver", "registry.example.com/team/gamma", "my-build", "my-build"},
+		{"PinnedPrefix", "orbsnetwork/gamma", "v1.3", DOCKER_TAG_NOT_FOUND},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, extractTagFromDockerImagesOutput(tt.repo, tt.tag, out))
+		})
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"fmt"
+	"github.com/orbs-network/gamma-cli/jsoncodec"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+const GAMMA_CONFIG_OVERRIDE_FLAG = "-override-config"
+
+// -override-config is given inline or read from a file with @path.json, invalid overrides fail here instead of crashing the container
+func getGammaConfigOverride() *jsoncodec.GammaConfigOverride {
+	override, warnings, err := readGammaConfigOverride(*flagOverrideConfig)
+	if err != nil {
+		die("Invalid -override-config.\n\n%s", err.Error())
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "WARNING: %s\n", warning.Error())
+	}
+	return override
+}
+
+func readGammaConfigOverride(value string) (*jsoncodec.GammaConfigOverride, []error, error) {
+	filename := GAMMA_CONFIG_OVERRIDE_FLAG
+	input := []byte(value)
+	if strings.HasPrefix(value, "@") {
+		filename = strings.TrimPrefix(value, "@")
+		bytes, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return nil, nil, err
+		}
+		input = bytes
+	}
+	return jsoncodec.UnmarshalGammaConfigOverride(filename, input, *flagSkipConfigCheck)
+}
+
+// values gamma-cli itself relies on, the local environment's virtual chain and the container port
+func getGammaConfigDefaults() map[string]interface{} {
+	return map[string]interface{}{
+		"virtual-chain-id": getDefaultLocalConfig()["VirtualChain"],
+		"http-address":     fmt.Sprintf(":%d", gammaHandlerOptions().containerPort),
+	}
+}
+
+func logGammaConfig(override *jsoncodec.GammaConfigOverride) {
+	log("Gamma server config:")
+	for _, line := range override.Describe(getGammaConfigDefaults()) {
+		log("  %s", line)
+	}
+
+	localEnv := getResolvedConfig().Environments[LOCAL_ENV_ID]
+	if vchain, found := override.Get("virtual-chain-id"); found && localEnv != nil && fmt.Sprintf("%v", vchain) != fmt.Sprintf("%d", localEnv.VirtualChain) {
+		fmt.Fprintf(os.Stderr, "WARNING: virtual-chain-id %v differs from VirtualChain %d of environment '%s' in '%s'\n", vchain, localEnv.VirtualChain, LOCAL_ENV_ID, getResolvedConfig().Source("Environments."+LOCAL_ENV_ID+".VirtualChain"))
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadGammaConfigOverride(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gamma-config")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "override.json")
+	require.NoError(t, ioutil.WriteFile(filename, []byte("{\n  \"virtual-chain-id\": 43\n}\n"), 0644))
+	invalidFilename := filepath.Join(dir, "invalid.json")
+	require.NoError(t, ioutil.WriteFile(invalidFilename, []byte("{\n  \"virtual-chain-id\": \"43\"\n}\n"), 0644))
+
+	tests := []struct {
+		name        string
+		value       string
+		expected    string
+		expectedErr string
+	}{
+		{"Inline", `{"virtual-chain-id":43}`, `{"virtual-chain-id":43}`, ""},
+		{"InlineInvalid", `{"virtual-chain-id":}`, "", GAMMA_CONFIG_OVERRIDE_FLAG + ":1:"},
+		{"File", "@" + filename, `{"virtual-chain-id":43}`, ""},
+		{"FileInvalid", "@" + invalidFilename, "", invalidFilename + ":2:3: virtual-chain-id"},
+		{"FileMissing", "@" + filepath.Join(dir, "missing.json"), "", "missing.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			override, _, err := readGammaConfigOverride(tt.value)
+			if tt.expectedErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			res, err := override.Marshal()
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, string(res))
+		})
+	}
+}
@@ -1,12 +1,15 @@
 module github.com/orbs-network/gamma-cli
 
-go 1.13
+go 1.16
 
 require (
+	github.com/BurntSushi/toml v1.3.2
 	github.com/orbs-network/crypto-lib-go v1.2.0
 	github.com/orbs-network/lean-helix-go v0.2.7
 	github.com/orbs-network/orbs-client-sdk-go v0.18.0
 	github.com/orbs-network/orbs-spec v0.0.0-20200312223140-a78d945bab99
 	github.com/pkg/errors v0.8.1
 	github.com/stretchr/testify v1.4.0
+	gopkg.in/yaml.v2 v2.2.2
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -40,7 +40,7 @@ func commandShowHelp(requiredOptions []string) {
 	showOptions()
 	fmt.Fprintf(os.Stderr, "\n")
 
-	fmt.Fprintf(os.Stderr, "Multiple environments (eg. local and testnet) can be defined in orbs-gamma-config.json configuration file.\n")
+	fmt.Fprintf(os.Stderr, "Multiple environments (eg. local and testnet) can be defined in orbs-gamma-config.json configuration files, run 'gamma-cli config show' to see which are used.\n")
 	fmt.Fprintf(os.Stderr, "See https://orbs.gitbook.io for more info.\n")
 	fmt.Fprintf(os.Stderr, "\n")
 
@@ -50,10 +50,10 @@ func commandShowHelp(requiredOptions []string) {
 func commandVersion(requiredOptions []string) {
 	log("gamma-cli version %s", GAMMA_CLI_VERSION)
 
-	gammaVersion := verifyDockerInstalled(gammaHandlerOptions(), gammaHandlerOptions().dockerRegistryTagsUrl)
+	gammaVersion := verifyDockerInstalled(gammaHandlerOptions())
 	log("Gamma server version %s (docker)", gammaVersion)
 
-	prismVersion := verifyDockerInstalled(prismHandlerOptions(), prismHandlerOptions().dockerRegistryTagsUrl)
+	prismVersion := verifyDockerInstalled(prismHandlerOptions())
 	log("Prism blockchain explorer version %s (docker)", prismVersion)
 }
 
@@ -0,0 +1,134 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"github.com/orbs-network/gamma-cli/jsoncodec"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// adds static headers to every request
+type headersRoundTripper struct {
+	headers   map[string]string
+	transport http.RoundTripper
+}
+
+func (rt *headersRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for name, value := range rt.headers {
+		req.Header.Set(name, value)
+	}
+	return rt.transport.RoundTrip(req)
+}
+
+// sends requests to the endpoints of the environment with its http settings, and requests to other hosts without them
+type endpointsRoundTripper struct {
+	hosts     map[string]bool
+	transport http.RoundTripper
+}
+
+func (rt *endpointsRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rt.hosts[req.URL.Host] {
+		return rt.transport.RoundTrip(req)
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// the client sdk sends its requests with http.DefaultClient, so the environment http settings are applied to it
+// scoped to the endpoints so headers and client certs never reach other hosts (eg. a docker registry)
+func configureHttpClient(conf *jsoncodec.ConfHttp, endpoints []string) {
+	if conf == nil {
+		return
+	}
+
+	// relative paths were already resolved against the config file declaring them
+	client, err := newHttpClient(conf, "")
+	if err != nil {
+		die("Invalid Http settings of environment '%s' in '%s'.\n\n%s", *flagEnv, getConfigSource("Http"), err.Error())
+	}
+	hosts := make(map[string]bool)
+	for _, endpoint := range endpoints {
+		if u, err := url.Parse(endpoint); err == nil {
+			hosts[u.Host] = true
+		}
+	}
+	http.DefaultClient.Timeout = client.Timeout
+	http.DefaultClient.Transport = &endpointsRoundTripper{hosts: hosts, transport: client.Transport}
+}
+
+func newHttpClient(conf *jsoncodec.ConfHttp, configDir string) (*http.Client, error) {
+	client := &http.Client{}
+	if conf.Timeout != "" {
+		timeout, err := time.ParseDuration(conf.Timeout)
+		if err != nil {
+			return nil, errors.Errorf("Timeout should be a duration (eg. 30s), current value: '%s'", conf.Timeout)
+		}
+		client.Timeout = timeout
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: conf.SkipVerify}
+	if conf.CaBundle != "" {
+		pem, err := ioutil.ReadFile(configRelativePath(configDir, conf.CaBundle))
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read CaBundle")
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("CaBundle '%s' does not contain any pem certificates", conf.CaBundle)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if conf.ClientCert != "" || conf.ClientKey != "" {
+		if conf.ClientCert == "" || conf.ClientKey == "" {
+			return nil, errors.New("ClientCert and ClientKey should be given together")
+		}
+		cert, err := tls.LoadX509KeyPair(configRelativePath(configDir, conf.ClientCert), configRelativePath(configDir, conf.ClientKey))
+		if err != nil {
+			return nil, errors.Wrap(err, "could not load ClientCert and ClientKey")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	if conf.Proxy != "" {
+		proxyUrl, err := url.Parse(conf.Proxy)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Proxy should be a url, current value: '%s'", conf.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyUrl)
+	}
+	client.Transport = transport
+
+	if len(conf.Headers) > 0 {
+		headers := make(map[string]string)
+		for name, value := range conf.Headers {
+			headers[name] = os.ExpandEnv(value)
+		}
+		client.Transport = &headersRoundTripper{headers: headers, transport: transport}
+	}
+
+	return client, nil
+}
+
+func configRelativePath(configDir string, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(configDir, path)
+}
@@ -0,0 +1,112 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"encoding/pem"
+	"github.com/orbs-network/gamma-cli/jsoncodec"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+func TestNewHttpClient_TlsAndHeaders(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "gamma-cli-http")
+	require.NoError(t, err, "temp dir should be created")
+	defer os.RemoveAll(dir)
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, ioutil.WriteFile(path.Join(dir, "ca.pem"), caBundle, 0644), "ca bundle should be written")
+
+	os.Setenv("GAMMA_TEST_GATEWAY_TOKEN", "secret")
+	defer os.Unsetenv("GAMMA_TEST_GATEWAY_TOKEN")
+
+	tests := []struct {
+		name     string
+		conf     *jsoncodec.ConfHttp
+		expected int
+	}{
+		{"CaBundle", &jsoncodec.ConfHttp{CaBundle: "ca.pem", Headers: map[string]string{"Authorization": "Bearer ${GAMMA_TEST_GATEWAY_TOKEN}"}}, http.StatusOK},
+		{"SkipVerify", &jsoncodec.ConfHttp{SkipVerify: true, Headers: map[string]string{"Authorization": "Bearer secret"}}, http.StatusOK},
+		{"MissingHeader", &jsoncodec.ConfHttp{SkipVerify: true}, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := newHttpClient(tt.conf, dir)
+			require.NoError(t, err, "client should be created")
+
+			res, err := client.Get(server.URL)
+			require.NoError(t, err, "request should reach the server")
+			res.Body.Close()
+			require.Equal(t, tt.expected, res.StatusCode)
+		})
+	}
+
+	client, err := newHttpClient(&jsoncodec.ConfHttp{}, dir)
+	require.NoError(t, err, "client should be created")
+	_, err = client.Get(server.URL)
+	require.Error(t, err, "unknown CA should be rejected by default")
+}
+
+func TestNewHttpClient_Invalid(t *testing.T) {
+	client, err := newHttpClient(&jsoncodec.ConfHttp{Timeout: "5s"}, ".")
+	require.NoError(t, err, "client should be created")
+	require.Equal(t, 5*time.Second, client.Timeout)
+
+	tests := []struct {
+		name string
+		conf *jsoncodec.ConfHttp
+	}{
+		{"Timeout", &jsoncodec.ConfHttp{Timeout: "5 seconds"}},
+		{"MissingCaBundle", &jsoncodec.ConfHttp{CaBundle: "missing.pem"}},
+		{"CertWithoutKey", &jsoncodec.ConfHttp{ClientCert: "cert.pem"}},
+		{"Proxy", &jsoncodec.ConfHttp{Proxy: "http://[::1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newHttpClient(tt.conf, ".")
+			require.Error(t, err, "invalid settings should fail")
+		})
+	}
+}
+
+func TestConfigureHttpClient_ScopedToEndpoints(t *testing.T) {
+	var authorization []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = append(authorization, r.Header.Get("Authorization"))
+	})
+	endpoint := httptest.NewServer(handler)
+	defer endpoint.Close()
+	other := httptest.NewServer(handler)
+	defer other.Close()
+
+	prevClient := *http.DefaultClient
+	defer func() { *http.DefaultClient = prevClient }()
+	configureHttpClient(&jsoncodec.ConfHttp{Headers: map[string]string{"Authorization": "Bearer secret"}}, []string{endpoint.URL})
+
+	for _, requestUrl := range []string{endpoint.URL, other.URL} {
+		res, err := http.Get(requestUrl)
+		require.NoError(t, err, "request should reach the server")
+		res.Body.Close()
+	}
+	res, err := (&dockerRegistry{}).do(other.URL, "")
+	require.NoError(t, err, "registry request should reach the server")
+	res.Body.Close()
+
+	require.Equal(t, []string{"Bearer secret", "", ""}, authorization, "environment headers should only be sent to its endpoints")
+}
@@ -0,0 +1,91 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package jsoncodec
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+type ContractAbi struct {
+	ContractName string
+	Methods      []*AbiMethod
+	Events       []*AbiMethod
+}
+
+type AbiMethod struct {
+	Name       string
+	Visibility string // PUBLIC, SYSTEM or EVENTS
+	Arguments  []*AbiArg
+	Returns    []string
+}
+
+type AbiArg struct {
+	Name string
+	Type string // same type names as Arg.Type
+}
+
+func (abi *ContractAbi) FindMethod(name string) *AbiMethod {
+	for _, method := range abi.Methods {
+		if method.Name == name {
+			return method
+		}
+	}
+	return nil
+}
+
+func UnmarshalContractAbi(bytes []byte) (*ContractAbi, error) {
+	var abi *ContractAbi
+	err := json.Unmarshal(bytes, &abi)
+	return abi, err
+}
+
+func MarshalContractAbi(abi *ContractAbi) ([]byte, error) {
+	return json.MarshalIndent(abi, "", "  ")
+}
+
+// reports every difference between a call and the exported method signature in the abi
+func (abi *ContractAbi) ValidateCall(contractName string, methodName string, args []*Arg) error {
+	var diffs []string
+	if contractName != abi.ContractName {
+		diffs = append(diffs, fmt.Sprintf("ContractName is '%s' but abi is of contract '%s'", contractName, abi.ContractName))
+	}
+
+	method := abi.FindMethod(methodName)
+	if method == nil {
+		diffs = append(diffs, fmt.Sprintf("MethodName '%s' is not exported by the contract", methodName))
+		return abiDiffError(diffs)
+	}
+	if method.Visibility != "PUBLIC" {
+		diffs = append(diffs, fmt.Sprintf("MethodName '%s' is exported as %s and cannot be called directly", methodName, method.Visibility))
+	}
+
+	if len(args) != len(method.Arguments) {
+		diffs = append(diffs, fmt.Sprintf("method '%s' expects %d arguments but %d were given", methodName, len(method.Arguments), len(args)))
+	}
+	for i := 0; i < len(args) && i < len(method.Arguments); i++ {
+		expected := method.Arguments[i]
+		if args[i] == nil {
+			diffs = append(diffs, fmt.Sprintf("argument %d (%s) should be of type %s but is null", i+1, expected.Name, expected.Type))
+			continue
+		}
+		if NativeArgType(args[i].Type) != expected.Type {
+			diffs = append(diffs, fmt.Sprintf("argument %d (%s) should be of type %s but is %s", i+1, expected.Name, expected.Type, args[i].Type))
+		}
+	}
+
+	return abiDiffError(diffs)
+}
+
+func abiDiffError(diffs []string) error {
+	if len(diffs) == 0 {
+		return nil
+	}
+	return errors.Errorf("Input does not match contract abi:\n - %s", strings.Join(diffs, "\n - "))
+}
@@ -0,0 +1,51 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package jsoncodec
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestContractAbiValidateCall(t *testing.T) {
+	abi := &ContractAbi{
+		ContractName: "MyToken",
+		Methods: []*AbiMethod{
+			{Name: "transfer", Visibility: "PUBLIC", Arguments: []*AbiArg{{Name: "amount", Type: "uint64"}, {Name: "to", Type: "bytes"}}},
+			{Name: "_init", Visibility: "SYSTEM", Arguments: []*AbiArg{}},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		contractName string
+		methodName   string
+		args         []*Arg
+		diffs        []string
+	}{
+		{"valid", "MyToken", "transfer", []*Arg{{"uint64", "10"}, {"bytes", "0x01"}}, nil},
+		{"valid gamma type", "MyToken", "transfer", []*Arg{{"uint64", "10"}, {"gamma:keys-file-address", "user2"}}, nil},
+		{"wrong contract", "MyCoin", "transfer", []*Arg{{"uint64", "10"}, {"bytes", "0x01"}}, []string{"ContractName is 'MyCoin'"}},
+		{"unknown method", "MyToken", "transferFrom", nil, []string{"MethodName 'transferFrom' is not exported"}},
+		{"system method", "MyToken", "_init", nil, []string{"exported as SYSTEM"}},
+		{"wrong count", "MyToken", "transfer", []*Arg{{"uint64", "10"}}, []string{"expects 2 arguments but 1 were given"}},
+		{"null argument", "MyToken", "transfer", []*Arg{{"uint64", "10"}, nil}, []string{"argument 2 (to) should be of type bytes but is null"}},
+		{"wrong types", "MyToken", "transfer", []*Arg{{"uint32", "10"}, {"gamma:env", "TO"}}, []string{"argument 1 (amount) should be of type uint64 but is uint32", "argument 2 (to) should be of type bytes but is gamma:env"}},
+	}
+
+	for _, cTest := range tests {
+		err := abi.ValidateCall(cTest.contractName, cTest.methodName, cTest.args)
+		if len(cTest.diffs) == 0 {
+			require.NoError(t, err, "validate %s should not fail", cTest.name)
+			continue
+		}
+		require.Error(t, err, "validate %s should fail", cTest.name)
+		for _, diff := range cTest.diffs {
+			require.Contains(t, err.Error(), diff, "validate %s should report diff", cTest.name)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package jsoncodec
+
+import "encoding/json"
+
+// maps a name to an address (hex string starting with 0x)
+type AddressBook map[string]string
+
+func UnmarshalAddressBook(bytes []byte) (AddressBook, error) {
+	book := make(AddressBook)
+	err := json.Unmarshal(bytes, &book)
+	return book, err
+}
+
+func MarshalAddressBook(book AddressBook) ([]byte, error) {
+	return json.MarshalIndent(book, "", "  ")
+}
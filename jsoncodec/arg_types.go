@@ -0,0 +1,186 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package jsoncodec
+
+import (
+	"encoding/hex"
+	"github.com/pkg/errors"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const ARG_ARRAY_SUFFIX = "Array"
+
+// a scalar argument type, every scalar type also has an array type named with ARG_ARRAY_SUFFIX (eg. uint32Array)
+type argType struct {
+	name       string
+	nativeType reflect.Type
+	unmarshal  func(value string, opts *ArgsOptions) (interface{}, error) // errors describe what the value should contain
+	marshal    func(value interface{}, opts *ArgsOptions) string
+}
+
+// the registry of argument types, in the order they are listed to users
+// it mirrors the argument types of the Orbs protocol (orbs-spec ArgumentType), a type the protocol adds needs one entry here
+var argTypes = []*argType{
+	{"uint32", reflect.TypeOf(uint32(0)), unmarshalUint32, marshalUint32},
+	{"uint64", reflect.TypeOf(uint64(0)), unmarshalUint64, marshalUint64},
+	{"uint256", reflect.TypeOf(&big.Int{}), unmarshalUint256Arg, marshalUint256Arg},
+	{"bool", reflect.TypeOf(false), unmarshalBool, marshalBool},
+	{"string", reflect.TypeOf(""), unmarshalString, marshalString},
+	{"bytes", reflect.TypeOf([]byte{}), unmarshalBytes, marshalBytes},
+	fixedBytesArgType(20),
+	fixedBytesArgType(32),
+}
+
+// gamma types are resolved by gamma-cli into a native type before sending, in the order they are listed to users
+var gammaArgTypes = []struct {
+	name       string
+	nativeType string
+}{
+	{"gamma:address", "bytes"},
+	{"gamma:keys-file-address", "bytes"},
+	{"gamma:keys-file-public-key", "bytes"},
+	{"gamma:book-address", "bytes"},
+	{"gamma:file-bytes", "bytes"},
+	{"gamma:file-string", "string"},
+	{"gamma:env", "string"},
+	{"gamma:sha256", "bytes32"},
+}
+
+var supported = "Supported types are: " + strings.Join(SupportedArgTypes(), " ")
+
+// scalar types, then array types, then gamma types
+func SupportedArgTypes() []string {
+	var res []string
+	for _, t := range argTypes {
+		res = append(res, t.name)
+	}
+	for _, t := range argTypes {
+		res = append(res, t.name+ARG_ARRAY_SUFFIX)
+	}
+	for _, t := range gammaArgTypes {
+		res = append(res, t.name)
+	}
+	return res
+}
+
+func findArgTypeByName(name string) *argType {
+	for _, t := range argTypes {
+		if t.name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+func findArgTypeByNativeType(nativeType reflect.Type) *argType {
+	for _, t := range argTypes {
+		if t.nativeType == nativeType {
+			return t
+		}
+	}
+	return nil
+}
+
+func fixedBytesArgType(size int) *argType {
+	name := "bytes" + strconv.Itoa(size)
+	description := name + " in a hex format (" + strconv.Itoa(size*2) + " hexes)"
+	nativeType := reflect.ArrayOf(size, reflect.TypeOf(byte(0)))
+	return &argType{
+		name:       name,
+		nativeType: nativeType,
+		unmarshal: func(value string, opts *ArgsOptions) (interface{}, error) {
+			valBytes, err := simpleDecodeHex(value)
+			if err != nil {
+				return nil, errors.Errorf("%s\nHex decoder returned error: %s\nCurrent value: '%s'", description, err.Error(), value)
+			}
+			if len(valBytes) != size {
+				return nil, errors.Errorf("%s\n Actual size : %d", description, len(valBytes))
+			}
+			val := reflect.New(nativeType).Elem()
+			reflect.Copy(val, reflect.ValueOf(valBytes))
+			return val.Interface(), nil
+		},
+		marshal: func(value interface{}, opts *ArgsOptions) string {
+			val := reflect.ValueOf(value)
+			valBytes := make([]byte, size)
+			reflect.Copy(reflect.ValueOf(valBytes), val)
+			return "0x" + hex.EncodeToString(valBytes)
+		},
+	}
+}
+
+func unmarshalUint32(value string, opts *ArgsOptions) (interface{}, error) {
+	val, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return nil, errors.Errorf("a numeric value\nCurrent value: '%s'", value)
+	}
+	return uint32(val), nil
+}
+
+func marshalUint32(value interface{}, opts *ArgsOptions) string {
+	return strconv.FormatUint(uint64(value.(uint32)), 10)
+}
+
+func unmarshalUint64(value string, opts *ArgsOptions) (interface{}, error) {
+	val, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return nil, errors.Errorf("a numeric value\nCurrent value: '%s'", value)
+	}
+	return val, nil
+}
+
+func marshalUint64(value interface{}, opts *ArgsOptions) string {
+	return strconv.FormatUint(value.(uint64), 10)
+}
+
+func unmarshalUint256Arg(value string, opts *ArgsOptions) (interface{}, error) {
+	return unmarshalUint256(value, opts)
+}
+
+func marshalUint256Arg(value interface{}, opts *ArgsOptions) string {
+	return marshalUint256(value.(*big.Int), opts)
+}
+
+func unmarshalBool(value string, opts *ArgsOptions) (interface{}, error) {
+	switch value {
+	case "1":
+		return true, nil
+	case "0":
+		return false, nil
+	}
+	return nil, errors.Errorf("1 or 0\nCurrent value: '%s'", value)
+}
+
+func marshalBool(value interface{}, opts *ArgsOptions) string {
+	if value.(bool) {
+		return "1"
+	}
+	return "0"
+}
+
+func unmarshalString(value string, opts *ArgsOptions) (interface{}, error) {
+	return value, nil
+}
+
+func marshalString(value interface{}, opts *ArgsOptions) string {
+	return value.(string)
+}
+
+func unmarshalBytes(value string, opts *ArgsOptions) (interface{}, error) {
+	val, err := simpleDecodeHex(value)
+	if err != nil {
+		return nil, errors.Errorf("bytes in hex format\nHex decoder returned error: %s\nCurrent value: '%s'", err.Error(), value)
+	}
+	return val, nil
+}
+
+func marshalBytes(value interface{}, opts *ArgsOptions) string {
+	return "0x" + hex.EncodeToString(value.([]byte))
+}
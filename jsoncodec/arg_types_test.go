@@ -0,0 +1,89 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package jsoncodec
+
+import (
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/stretchr/testify/require"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// a valid input value of every registered type
+var argTypeSamples = map[string][]string{
+	"uint32":  {"0", "4294967295"},
+	"uint64":  {"0", "18446744073709551615"},
+	"uint256": {"0x0000000000000000000000000000000000000000000000000000000000000000", "0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"},
+	"bool":    {"0", "1"},
+	"string":  {"", "hello"},
+	"bytes":   {"0x", "0x00ff"},
+	"bytes20": {"0x" + strings.Repeat("00", 20), "0x" + strings.Repeat("a1", 20)},
+	"bytes32": {"0x" + strings.Repeat("00", 32), "0x" + strings.Repeat("b2", 32)},
+}
+
+func TestArgTypes_ScalarRoundTrip(t *testing.T) {
+	for _, argType := range argTypes {
+		samples, found := argTypeSamples[argType.name]
+		require.True(t, found, "type %s should have samples", argType.name)
+
+		for _, sample := range samples {
+			t.Run(argType.name+"/"+sample, func(t *testing.T) {
+				natives, err := UnmarshalArgs([]*Arg{{argType.name, sample}}, nil, nil, nil)
+				require.NoError(t, err, "unmarshal should succeed")
+				require.Equal(t, argType.nativeType, reflect.TypeOf(natives[0]), "unmarshal should return the registered native type")
+
+				args, err := MarshalArgs(natives, nil)
+				require.NoError(t, err, "marshal should succeed")
+				require.Equal(t, []*Arg{{argType.name, sample}}, args, "value should round trip")
+			})
+		}
+	}
+}
+
+func TestArgTypes_ArrayRoundTrip(t *testing.T) {
+	for _, argType := range argTypes {
+		t.Run(argType.name+ARG_ARRAY_SUFFIX, func(t *testing.T) {
+			samples := argTypeSamples[argType.name]
+			var values []interface{}
+			for _, sample := range samples {
+				values = append(values, sample)
+			}
+
+			natives, err := UnmarshalArgs([]*Arg{{argType.name + ARG_ARRAY_SUFFIX, values}}, nil, nil, nil)
+			require.NoError(t, err, "unmarshal should succeed")
+			require.Equal(t, reflect.SliceOf(argType.nativeType), reflect.TypeOf(natives[0]), "unmarshal should return a slice of the registered native type")
+
+			args, err := MarshalArgs(natives, nil)
+			require.NoError(t, err, "marshal should succeed")
+			require.Equal(t, []*Arg{{argType.name + ARG_ARRAY_SUFFIX, samples}}, args, "values should round trip")
+		})
+	}
+}
+
+func TestArgTypes_EncodableByProtocol(t *testing.T) {
+	for _, argType := range argTypes {
+		natives, err := UnmarshalArgs([]*Arg{
+			{argType.name, argTypeSamples[argType.name][1]},
+			{argType.name + ARG_ARRAY_SUFFIX, []interface{}{argTypeSamples[argType.name][1]}},
+		}, nil, nil, nil)
+		require.NoError(t, err, "unmarshal of %s should succeed", argType.name)
+
+		_, err = protocol.ArgumentArrayFromNatives(natives)
+		require.NoError(t, err, "protocol should encode %s and %s%s", argType.name, argType.name, ARG_ARRAY_SUFFIX)
+	}
+}
+
+func TestArgTypes_Supported(t *testing.T) {
+	require.Equal(t, "Supported types are: uint32 uint64 uint256 bool string bytes bytes20 bytes32 uint32Array uint64Array uint256Array boolArray stringArray bytesArray bytes20Array bytes32Array gamma:address gamma:keys-file-address gamma:keys-file-public-key gamma:book-address gamma:file-bytes gamma:file-string gamma:env gamma:sha256", supported)
+}
+
+func TestArgTypes_InvalidArrayElement(t *testing.T) {
+	_, err := UnmarshalArgs([]*Arg{{"uint32Array", []interface{}{"1", 2.0}}}, nil, nil, nil)
+	require.Error(t, err, "non string array elements should fail")
+	require.Contains(t, err.Error(), "element 2 should be a string")
+}
@@ -7,24 +7,57 @@
 package jsoncodec
 
 import (
+	"crypto/sha256"
 	"encoding/hex"
 	"github.com/orbs-network/crypto-lib-go/crypto/encoding"
 	"github.com/pkg/errors"
-	"math/big"
+	"io/ioutil"
+	"os"
 	"reflect"
-	"strconv"
 	"strings"
 )
 
-const supported = "Supported types are: uint32 uint64 uint256 bool string bytes bytes20 bytes32 uint32Array uint64Array uint256Array boolArray stringArray bytesArray bytes20Array bytes32Array gamma:address gamma:keys-file-address"
-
 type Arg struct {
 	Type  string
 	Value interface{}
 }
 
+// options of reading and writing argument values, nil uses the defaults
+type ArgsOptions struct {
+	Uint256Units    map[string]int // uint256 input units in addition to wei, gwei and ether, mapped to their number of decimals
+	Uint256Output   string         // format of uint256 output: hex (default), decimal, a unit (eg. ether) or a number of decimals
+	AllowLocalReads bool           // gamma:env and gamma:file-* arguments may read environment variables and files into the transaction
+}
+
+func (o *ArgsOptions) Validate() error {
+	return o.validateUint256()
+}
+
+// input files are often shared, so they cannot read local secrets into a signed transaction without an explicit opt-in
+func (o *ArgsOptions) checkLocalRead(i int, arg *Arg) error {
+	if o != nil && o.AllowLocalReads {
+		return nil
+	}
+	return errors.Errorf("Argument %d of type %s reads '%s' of this machine into the transaction, which requires the -allow-local-args option", i+1, arg.Type, arg.Value)
+}
+
+func NativeArgType(argType string) string {
+	for _, t := range gammaArgTypes {
+		if t.name == argType {
+			return t.nativeType
+		}
+	}
+	return argType
+}
+
 func isArgsInputStructureValid(args []*Arg) error {
 	for i, arg := range args {
+		if arg == nil {
+			return errors.Errorf("Argument %d is null", i+1)
+		}
+		if arg.Value == nil {
+			return errors.Errorf("Argument %d's Value is missing or null", i+1)
+		}
 		rValue := reflect.TypeOf(arg.Value).String()
 		if strings.HasSuffix(arg.Type, "Array") {
 			if rValue != "[]interface {}" {
@@ -37,181 +70,47 @@ func isArgsInputStructureValid(args []*Arg) error {
 	return nil
 }
 
-func unmarshalScalar(argType, value string) (interface{}, error) {
-	switch argType {
-	case "uint32":
-		val, err := strconv.ParseUint(value, 10, 32)
-		if err != nil {
-			return nil, errors.Errorf("a numeric value\nCurrent value: '%s'", value)
-		}
-		return uint32(val), nil
-	case "uint64":
-		val, err := strconv.ParseUint(value, 10, 64)
-		if err != nil {
-			return nil, errors.Errorf("a numeric value\nCurrent value: '%s'", value)
-		}
-		return val, nil
-	case "string":
-		return value, nil
-	case "bytes":
-		val, err := simpleDecodeHex(value)
-		if err != nil {
-			return nil, errors.Errorf("bytes in hex format\nHex decoder returned error: %s\nCurrent value: '%s'", err.Error(), value)
-		}
-		return val, nil
-	case "bool":
-		if value == "1" {
-			return true, nil
-		} else if value == "0" {
-			return false, nil
-		} else {
-			return nil, errors.Errorf("1 or 0\nCurrent value: '%s'", value)
-		}
-	case "uint256":
-		valBytes, err := simpleDecodeHex(value)
-		if err != nil {
-			return nil, errors.Errorf("uint256 value in bytes in a hex format (64 hexes)\nHex decoder returned error: %s\nCurrent value: '%s'", err.Error(), value)
-		}
-		if len(valBytes) != 32 {
-			return nil, errors.Errorf("uint256 value in bytes in a hex format (64 hexes)\n Actual size : %d", len(valBytes))
-		}
-		val := big.NewInt(0)
-		val.SetBytes(valBytes)
-		return val, nil
-	case "bytes20":
-		valBytes, err := simpleDecodeHex(value)
-		if err != nil {
-			return nil, errors.Errorf("bytes20 in a hex format (40 hexes)\nHex decoder returned error: %s\nCurrent value: '%s'", err.Error(), value)
-		}
-		if len(valBytes) != 20 {
-			return nil, errors.Errorf("bytes20 in a hex format (40 hexes)\n Actual size : %d", len(valBytes))
-		}
-		var val [20]byte
-		copy(val[:], valBytes)
-		return val, nil
-	case "bytes32":
-		valBytes, err := simpleDecodeHex(value)
-		if err != nil {
-			return nil, errors.Errorf("bytes32 in a hex format (64 hexes)\nHex decoder returned error: %s\nCurrent value: '%s'", err.Error(), value)
-		}
-		if len(valBytes) != 32 {
-			return nil, errors.Errorf("bytes32 in a hex format (64 hexes)\n Actual size : %d", len(valBytes))
-		}
-		var val [32]byte
-		copy(val[:], valBytes)
-		return val, nil
-	default:
-		return nil, errors.Errorf("a known type. '%s' is unsupported\n%s", argType, supported)
+func unmarshalScalar(argTypeName string, value string, opts *ArgsOptions) (interface{}, error) {
+	t := findArgTypeByName(argTypeName)
+	if t == nil {
+		return nil, errors.Errorf("a known type. '%s' is unsupported\n%s", argTypeName, supported)
 	}
+	return t.unmarshal(value, opts)
 }
 
-func unmarshalArray(argType string, argValues []interface{}) (interface{}, error) {
-	switch argType {
-	case "uint32Array":
-		var argArrayRes []uint32
-		for j, argValue := range argValues {
-			val, err := strconv.ParseUint(argValue.(string), 10, 32)
-			if err != nil {
-				return nil, errors.Errorf("element %d should be a string containing a numeric value\nCurrent value: '%s'", j+1, argValue)
-			}
-			argArrayRes = append(argArrayRes, uint32(val))
-		}
-		return argArrayRes, nil
-	case "uint64Array":
-		var argArrayRes []uint64
-		for j, argValue := range argValues {
-			val, err := strconv.ParseUint(argValue.(string), 10, 64)
-			if err != nil {
-				return nil, errors.Errorf("element %d should be a string containing a numeric value\nCurrent value: '%s'", j+1, argValue)
-			}
-			argArrayRes = append(argArrayRes, val)
-		}
-		return argArrayRes, nil
-	case "stringArray":
-		var argArrayRes []string
-		for _, argValue := range argValues {
-			argArrayRes = append(argArrayRes, argValue.(string))
-		}
-		return argArrayRes, nil
-	case "bytesArray":
-		var argArrayRes [][]byte
-		for j, argValue := range argValues {
-			val, err := simpleDecodeHex(argValue.(string))
-			if err != nil {
-				return nil, errors.Errorf("element %d should be a string containing bytes in hex format\nHex decoder returned error: %s\nCurrent value: '%s'", j+1, err.Error(), argValue)
-			}
-			argArrayRes = append(argArrayRes, val)
-		}
-		return argArrayRes, nil
-	case "boolArray":
-		var argArrayRes []bool
-		for j, argValue := range argValues {
-			s := argValue.(string)
-			if s == "1" {
-				argArrayRes = append(argArrayRes, true)
-			} else if s == "0" {
-				argArrayRes = append(argArrayRes, false)
-			} else {
-				return nil, errors.Errorf("element %d should be a string containing 1 or 0\nCurrent value: '%s'", j+1, argValue)
-			}
-		}
-		return argArrayRes, nil
-	case "uint256Array":
-		var argArrayRes []*big.Int
-		for j, argValue := range argValues {
-			valBytes, err := simpleDecodeHex(argValue.(string))
-			if err != nil {
-				return nil, errors.Errorf("element %d should be a string containing uint256 in hex format (64 hexes)\nHex decoder returned error: %s\nCurrent value: '%s'", j+1, err.Error(), argValue)
-			}
-			if len(valBytes) != 32 {
-				return nil, errors.Errorf("element %d should be a string containing uint256 in a hex format (64 hexes)\n Actual size : %d", j+1, len(valBytes))
-			}
-			val := big.NewInt(0)
-			val.SetBytes(valBytes)
-			argArrayRes = append(argArrayRes, val)
-		}
-		return argArrayRes, nil
-	case "bytes20Array":
-		var argArrayRes [][20]byte
-		for j, argValue := range argValues {
-			valBytes, err := simpleDecodeHex(argValue.(string))
-			if err != nil {
-				return nil, errors.Errorf("element %d should be a string containing bytes20 in hex format (40 hexes)\nHex decoder returned error: %s\nCurrent value: '%s'", j+1, err.Error(), argValue)
-			}
-			if len(valBytes) != 20 {
-				return nil, errors.Errorf("element %d should be a string containing bytes20 in a hex format (40 hexes)\n Actual size : %d", j+1, len(valBytes))
-			}
-			var val [20]byte
-			copy(val[:], valBytes)
-			argArrayRes = append(argArrayRes, val)
+// returns a slice of the native type of the array elements (eg. []uint32 for uint32Array)
+func unmarshalArray(argTypeName string, argValues []interface{}, opts *ArgsOptions) (interface{}, error) {
+	t := findArgTypeByName(strings.TrimSuffix(argTypeName, ARG_ARRAY_SUFFIX))
+	if t == nil || !strings.HasSuffix(argTypeName, ARG_ARRAY_SUFFIX) {
+		return nil, errors.Errorf("a known type. '%s' is unsupported\n%s", argTypeName, supported)
+	}
+
+	res := reflect.MakeSlice(reflect.SliceOf(t.nativeType), 0, len(argValues))
+	for j, argValue := range argValues {
+		s, ok := argValue.(string)
+		if !ok {
+			return nil, errors.Errorf("element %d should be a string\nCurrent value: '%v'", j+1, argValue)
 		}
-		return argArrayRes, nil
-	case "bytes32Array":
-		var argArrayRes [][32]byte
-		for j, argValue := range argValues {
-			valBytes, err := simpleDecodeHex(argValue.(string))
-			if err != nil {
-				return nil, errors.Errorf("element %d should be a string containing bytes32 in hex format (64 hexes)\nHex decoder returned error: %s\nCurrent value: '%s'", j+1, err.Error(), argValue)
-			}
-			if len(valBytes) != 32 {
-				return nil, errors.Errorf("element %d should be a string containing bytes32 in a hex format (64 hexes)\n Actual size : %d", j+1, len(valBytes))
-			}
-			var val [32]byte
-			copy(val[:], valBytes)
-			argArrayRes = append(argArrayRes, val)
+		val, err := t.unmarshal(s, opts)
+		if err != nil {
+			return nil, errors.Errorf("element %d should be a string containing %s", j+1, err.Error())
 		}
-		return argArrayRes, nil
-	default:
-		return nil, errors.Errorf("a known type. '%s' is unsupported\n%s", argType, supported)
+		res = reflect.Append(res, reflect.ValueOf(val))
 	}
+	return res.Interface(), nil
 }
 
-func UnmarshalArgs(args []*Arg, getTestKeyFromFile func(string) *RawKey) ([]interface{}, error) {
+func UnmarshalArgs(args []*Arg, getTestKeyFromFile func(string) *RawKey, getAddressFromBook func(string) []byte, opts *ArgsOptions) ([]interface{}, error) {
 	if err := isArgsInputStructureValid(args); err != nil {
 		return nil, err
 	}
 	var res []interface{}
 	for i, arg := range args {
+		if arg.Type == "gamma:file-bytes" || arg.Type == "gamma:file-string" || arg.Type == "gamma:env" {
+			if err := opts.checkLocalRead(i, arg); err != nil {
+				return nil, err
+			}
+		}
 		if arg.Type == "gamma:address" {
 			val, err := encoding.DecodeHex(arg.Value.(string))
 			if err != nil {
@@ -221,14 +120,39 @@ func UnmarshalArgs(args []*Arg, getTestKeyFromFile func(string) *RawKey) ([]inte
 		} else if arg.Type == "gamma:keys-file-address" {
 			key := getTestKeyFromFile(arg.Value.(string))
 			res = append(res, key.Address)
+		} else if arg.Type == "gamma:keys-file-public-key" {
+			key := getTestKeyFromFile(arg.Value.(string))
+			res = append(res, key.PublicKey)
+		} else if arg.Type == "gamma:book-address" {
+			res = append(res, getAddressFromBook(arg.Value.(string)))
+		} else if arg.Type == "gamma:file-bytes" {
+			val, err := ioutil.ReadFile(arg.Value.(string))
+			if err != nil {
+				return nil, errors.Errorf("Value of argument %d should be a path of a readable file\nRead returned error: %s\n\nCurrent value: '%s'", i+1, err.Error(), arg.Value)
+			}
+			res = append(res, val)
+		} else if arg.Type == "gamma:file-string" {
+			val, err := ioutil.ReadFile(arg.Value.(string))
+			if err != nil {
+				return nil, errors.Errorf("Value of argument %d should be a path of a readable file\nRead returned error: %s\n\nCurrent value: '%s'", i+1, err.Error(), arg.Value)
+			}
+			res = append(res, string(val))
+		} else if arg.Type == "gamma:env" {
+			val, found := os.LookupEnv(arg.Value.(string))
+			if !found {
+				return nil, errors.Errorf("Value of argument %d should be the name of a set environment variable\n\nCurrent value: '%s'", i+1, arg.Value)
+			}
+			res = append(res, val)
+		} else if arg.Type == "gamma:sha256" {
+			res = append(res, sha256.Sum256([]byte(arg.Value.(string))))
 		} else if strings.HasSuffix(arg.Type, "Array") {
-			valArray, err := unmarshalArray(arg.Type, arg.Value.([]interface{}))
+			valArray, err := unmarshalArray(arg.Type, arg.Value.([]interface{}), opts)
 			if err != nil {
 				return nil, errors.Errorf("Value of array argument %d, %s", i+1, err.Error())
 			}
 			res = append(res, valArray)
 		} else {
-			val, err := unmarshalScalar(arg.Type, arg.Value.(string))
+			val, err := unmarshalScalar(arg.Type, arg.Value.(string), opts)
 			if err != nil {
 				return nil, errors.Errorf("Value of argument %d should be a string containing %s", i+1, err.Error())
 			}
@@ -238,92 +162,32 @@ func UnmarshalArgs(args []*Arg, getTestKeyFromFile func(string) *RawKey) ([]inte
 	return res, nil
 }
 
-func MarshalArgs(arguments []interface{}) ([]*Arg, error) {
+func MarshalArgs(arguments []interface{}, opts *ArgsOptions) ([]*Arg, error) {
 	var res []*Arg
 	for i, arg := range arguments {
-		if reflect.TypeOf(arg).Kind() == reflect.Slice { // all []Type including []byte
+		argValue := reflect.ValueOf(arg)
+		if t := findArgTypeByNativeType(reflect.TypeOf(arg)); t != nil {
+			res = append(res, &Arg{t.name, t.marshal(arg, opts)})
+		} else if t := findArrayElementArgType(argValue); t != nil {
 			var arrArguments []string
-			switch arg := arg.(type) {
-			case []byte:
-				res = append(res, &Arg{"bytes", "0x" + hex.EncodeToString(arg)})
-			case []uint32:
-				for _, v := range arg {
-					arrArguments = append(arrArguments, strconv.FormatUint(uint64(v), 10))
-				}
-				res = append(res, &Arg{"uint32Array", arrArguments})
-			case []uint64:
-				for _, v := range arg {
-					arrArguments = append(arrArguments, strconv.FormatUint(v, 10))
-				}
-				res = append(res, &Arg{"uint64Array", arrArguments})
-			case []string:
-				res = append(res, &Arg{"stringArray", arg})
-			case [][]byte:
-				for _, v := range arg {
-					arrArguments = append(arrArguments, "0x"+hex.EncodeToString(v))
-				}
-				res = append(res, &Arg{"bytesArray", arrArguments})
-			case []bool:
-				for _, v := range arg {
-					if v {
-						arrArguments = append(arrArguments, "1")
-					} else {
-						arrArguments = append(arrArguments, "0")
-					}
-				}
-				res = append(res, &Arg{"boolArray", arrArguments})
-			case []*big.Int:
-				val := [32]byte{}
-				for _, v := range arg {
-					b := v.Bytes()
-					copy(val[32-len(b):], b)
-					arrArguments = append(arrArguments, "0x"+hex.EncodeToString(val[:]))
-				}
-				res = append(res, &Arg{"uint256Array", arrArguments})
-			case [][20]byte:
-				for _, v := range arg {
-					arrArguments = append(arrArguments, "0x"+hex.EncodeToString(v[:]))
-				}
-				res = append(res, &Arg{"bytes20Array", arrArguments})
-			case [][32]byte:
-				for _, v := range arg {
-					arrArguments = append(arrArguments, "0x"+hex.EncodeToString(v[:]))
-				}
-				res = append(res, &Arg{"bytes32Array", arrArguments})
-			default:
-				return nil, errors.Errorf("Type of argument %d '%T' is unsupported\n\n%s", i+1, arg, supported)
+			for j := 0; j < argValue.Len(); j++ {
+				arrArguments = append(arrArguments, t.marshal(argValue.Index(j).Interface(), opts))
 			}
+			res = append(res, &Arg{t.name + ARG_ARRAY_SUFFIX, arrArguments})
 		} else {
-			switch arg := arg.(type) {
-			case uint32:
-				res = append(res, &Arg{"uint32", strconv.FormatUint(uint64(arg), 10)})
-			case uint64:
-				res = append(res, &Arg{"uint64", strconv.FormatUint(arg, 10)})
-			case string:
-				res = append(res, &Arg{"string", arg})
-			case bool:
-				if arg {
-					res = append(res, &Arg{"bool", "1"})
-				} else {
-					res = append(res, &Arg{"bool", "0"})
-				}
-			case *big.Int:
-				val := [32]byte{}
-				b := arg.Bytes()
-				copy(val[32-len(b):], b)
-				res = append(res, &Arg{"uint256", "0x" + hex.EncodeToString(val[:])})
-			case [20]byte:
-				res = append(res, &Arg{"bytes20", "0x" + hex.EncodeToString(arg[:])})
-			case [32]byte:
-				res = append(res, &Arg{"bytes32", "0x" + hex.EncodeToString(arg[:])})
-			default:
-				return nil, errors.Errorf("Type of argument %d '%T' is unsupported\n\n%s", i+1, arg, supported)
-			}
+			return nil, errors.Errorf("Type of argument %d '%T' is unsupported\n\n%s", i+1, arg, supported)
 		}
 	}
 	return res, nil
 }
 
+func findArrayElementArgType(value reflect.Value) *argType {
+	if !value.IsValid() || value.Kind() != reflect.Slice {
+		return nil
+	}
+	return findArgTypeByNativeType(value.Type().Elem())
+}
+
 func simpleDecodeHex(value string) ([]byte, error) {
 	if strings.HasPrefix(value, "0x") {
 		value = value[2:]
@@ -7,8 +7,11 @@
 package jsoncodec
 
 import (
+	"crypto/sha256"
 	"github.com/stretchr/testify/require"
+	"io/ioutil"
 	"math/big"
+	"os"
 	"testing"
 )
 
@@ -58,7 +61,7 @@ func TestArgumentsUnMarshalingTypes_InputCorrectlyStructured(t *testing.T) {
 		argList := []*Arg{cTest.arg}
 		nativeList := []interface{}{cTest.native}
 
-		resNativeList, err := UnmarshalArgs(argList, func(string) *RawKey { return nil })
+		resNativeList, err := UnmarshalArgs(argList, func(string) *RawKey { return nil }, func(string) []byte { return nil }, nil)
 		if cTest.shouldErr {
 			require.Error(t, err, "unmarshal %s should fail", cTest.name)
 		} else {
@@ -77,12 +80,14 @@ func TestArgumentsUnMarshalingTypes_IncorrectInputStructure(t *testing.T) {
 		{"array-type with non-array-input", &Arg{"uint32Array", "19480514"}},
 		{"non-array-input is not string", &Arg{"uint64", 19480514000000000}},
 		{"array input is not string array", &Arg{"uint64Array", []uint32{10, 20}}},
+		{"missing value", &Arg{"uint32", nil}},
+		{"null argument", nil},
 	}
 
 	for _, cTest := range tests {
 		argList := []*Arg{cTest.arg}
 
-		_, err := UnmarshalArgs(argList, func(string) *RawKey { return nil })
+		_, err := UnmarshalArgs(argList, func(string) *RawKey { return nil }, func(string) []byte { return nil }, nil)
 		require.Error(t, err, "unmarshal %s should fail", cTest.name)
 	}
 }
@@ -119,7 +124,7 @@ func TestArgumentsMarshaling_GoodFlow(t *testing.T) {
 		argList := []*Arg{cTest.arg}
 		nativeList := []interface{}{cTest.native}
 
-		resArgList, err := MarshalArgs(nativeList)
+		resArgList, err := MarshalArgs(nativeList, nil)
 		require.NoError(t, err, "unmarshal %s should not fail", cTest.name)
 		require.EqualValues(t, argList, resArgList)
 	}
@@ -127,6 +132,156 @@ func TestArgumentsMarshaling_GoodFlow(t *testing.T) {
 
 func TestArgumentsMarshaling_BadFlow(t *testing.T) {
 	nativeList := []interface{}{1.0}
-	_, err := MarshalArgs(nativeList)
+	_, err := MarshalArgs(nativeList, nil)
 	require.Error(t, err, "unmarshal %s should fail")
 }
+
+func TestArgumentsUnMarshalingTypes_GammaAddressTypes(t *testing.T) {
+	keyAddress := []byte{0x01, 0x02, 0x03}
+	bookAddress := []byte{0x04, 0x05, 0x06}
+	getTestKeyFromFile := func(id string) *RawKey {
+		require.Equal(t, "user1", id, "key id should be passed as is")
+		return &RawKey{Address: keyAddress}
+	}
+	getAddressFromBook := func(name string) []byte {
+		require.Equal(t, "exchange", name, "book name should be passed as is")
+		return bookAddress
+	}
+
+	argList := []*Arg{
+		{"gamma:address", "0x0708090a"},
+		{"gamma:keys-file-address", "user1"},
+		{"gamma:book-address", "exchange"},
+	}
+	resNativeList, err := UnmarshalArgs(argList, getTestKeyFromFile, getAddressFromBook, nil)
+	require.NoError(t, err, "unmarshal gamma address types should not fail")
+	require.EqualValues(t, []interface{}{[]byte{0x07, 0x08, 0x09, 0x0a}, keyAddress, bookAddress}, resNativeList)
+}
+
+func TestArgumentsUnMarshalingTypes_GammaDerivedTypes(t *testing.T) {
+	file, err := ioutil.TempFile("", "gamma-arg")
+	require.NoError(t, err, "temp file should be created")
+	defer os.Remove(file.Name())
+	_, err = file.WriteString("hello file")
+	require.NoError(t, err, "temp file should be written")
+	file.Close()
+
+	os.Setenv("GAMMA_TEST_ARG_ENV", "hello env")
+	defer os.Unsetenv("GAMMA_TEST_ARG_ENV")
+
+	publicKey := []byte{0x01, 0x02, 0x03}
+	getTestKeyFromFile := func(string) *RawKey { return &RawKey{PublicKey: publicKey} }
+
+	argList := []*Arg{
+		{"gamma:keys-file-public-key", "user1"},
+		{"gamma:file-bytes", file.Name()},
+		{"gamma:file-string", file.Name()},
+		{"gamma:env", "GAMMA_TEST_ARG_ENV"},
+		{"gamma:sha256", "hello"},
+	}
+	opts := &ArgsOptions{AllowLocalReads: true}
+	resNativeList, err := UnmarshalArgs(argList, getTestKeyFromFile, func(string) []byte { return nil }, opts)
+	require.NoError(t, err, "unmarshal gamma derived types should not fail")
+	require.EqualValues(t, []interface{}{publicKey, []byte("hello file"), "hello file", "hello env", sha256.Sum256([]byte("hello"))}, resNativeList)
+
+	_, err = UnmarshalArgs([]*Arg{{"gamma:file-bytes", file.Name() + "-missing"}}, getTestKeyFromFile, nil, opts)
+	require.Error(t, err, "unmarshal of missing file should fail")
+
+	_, err = UnmarshalArgs([]*Arg{{"gamma:env", "GAMMA_TEST_ARG_ENV_MISSING"}}, getTestKeyFromFile, nil, opts)
+	require.Error(t, err, "unmarshal of missing environment variable should fail")
+}
+
+func TestArgumentsUnMarshalingTypes_LocalReadsRequireOptIn(t *testing.T) {
+	os.Setenv("GAMMA_TEST_ARG_ENV", "hello env")
+	defer os.Unsetenv("GAMMA_TEST_ARG_ENV")
+
+	for _, argType := range []string{"gamma:file-bytes", "gamma:file-string", "gamma:env"} {
+		t.Run(argType, func(t *testing.T) {
+			for _, opts := range []*ArgsOptions{nil, {}} {
+				_, err := UnmarshalArgs([]*Arg{{argType, "GAMMA_TEST_ARG_ENV"}}, nil, nil, opts)
+				require.Error(t, err, "local reads should fail without the opt-in")
+				require.Contains(t, err.Error(), "-allow-local-args", "error should name the option allowing it")
+			}
+		})
+	}
+}
+
+func TestArgumentsUnMarshalingTypes_Uint256Decimal(t *testing.T) {
+	opts := &ArgsOptions{Uint256Units: map[string]int{"Token": 8}}
+	tenToThe18 := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	maxUint256Text := "115792089237316195423570985008687907853269984665640564039457584007913129639935"
+
+	tests := []struct {
+		name      string
+		shouldErr bool
+		value     string
+		expected  *big.Int
+	}{
+		{"decimal", false, "1000", big.NewInt(1000)},
+		{"hex with prefix", false, "0x00000000000000000000000000000000000000000000000000000000000003e8", big.NewInt(1000)},
+		{"exponent", false, "1.5e18", new(big.Int).Mul(big.NewInt(15), new(big.Int).Div(tenToThe18, big.NewInt(10)))},
+		{"ether unit", false, "10 ether", new(big.Int).Mul(big.NewInt(10), tenToThe18)},
+		{"gwei unit", false, "2gwei", big.NewInt(2000000000)},
+		{"configured unit", false, "1.25 token", big.NewInt(125000000)},
+		{"max value", false, maxUint256Text, maxUint256},
+		{"fraction-fail", true, "1.5", nil},
+		{"negative-fail", true, "-1", nil},
+		{"unknown-unit-fail", true, "10 bananas", nil},
+		{"overflow-fail", true, maxUint256Text + "0", nil},
+		{"huge-exponent-fail", true, "1e1000000000", nil},
+		{"64 digits as hex", false, "0000000000000000000000000000000000000000000000000000000000001000", big.NewInt(4096)},
+		{"64 digits with unit", false, "0000000000000000000000000000000000000000000000000000000000001000 wei", big.NewInt(1000)},
+		{"64 digits with prefix", false, "0x0000000000000000000000000000000000000000000000000000000000001000", big.NewInt(4096)},
+	}
+
+	for _, cTest := range tests {
+		res, err := UnmarshalArgs([]*Arg{{"uint256", cTest.value}}, nil, nil, opts)
+		if cTest.shouldErr {
+			require.Error(t, err, "unmarshal %s should fail", cTest.name)
+		} else {
+			require.NoError(t, err, "unmarshal %s should not fail", cTest.name)
+			require.Zero(t, cTest.expected.Cmp(res[0].(*big.Int)), "unmarshal %s expected %s but got %s", cTest.name, cTest.expected, res[0])
+		}
+	}
+}
+
+func TestArgumentsMarshaling_Uint256Output(t *testing.T) {
+	value := new(big.Int).Mul(big.NewInt(15), new(big.Int).Exp(big.NewInt(10), big.NewInt(17), nil))
+	tests := []struct {
+		name     string
+		output   string
+		value    *big.Int
+		expected string
+	}{
+		{"default", "", big.NewInt(1000), "0x00000000000000000000000000000000000000000000000000000000000003e8"},
+		{"hex", "hex", big.NewInt(1000), "0x00000000000000000000000000000000000000000000000000000000000003e8"},
+		{"decimal", "decimal", value, "1500000000000000000"},
+		{"0 decimals", "0", value, "1500000000000000000"},
+		{"18 decimals", "18", value, "1.5e18"},
+		{"18 decimals whole", "18", new(big.Int).Mul(value, big.NewInt(2)), "3e18"},
+		{"18 decimals small", "18", big.NewInt(1), "0.000000000000000001e18"},
+		{"ether", "ether", value, "1.5 ether"},
+		{"configured unit", "token", big.NewInt(125000000), "1.25 token"},
+		{"2 decimals zero", "2", big.NewInt(0), "0e2"},
+	}
+
+	for _, cTest := range tests {
+		opts := &ArgsOptions{Uint256Units: map[string]int{"token": 8}, Uint256Output: cTest.output}
+		require.NoError(t, opts.Validate(), "options %s should be valid", cTest.name)
+
+		res, err := MarshalArgs([]interface{}{cTest.value, []*big.Int{cTest.value}}, opts)
+		require.NoError(t, err, "marshal %s should not fail", cTest.name)
+		require.EqualValues(t, []*Arg{{"uint256", cTest.expected}, {"uint256Array", []string{cTest.expected}}}, res, "marshal %s", cTest.name)
+
+		natives, err := UnmarshalArgs(res[:1], nil, nil, opts)
+		require.NoError(t, err, "output %s should be valid input", cTest.name)
+		require.Zero(t, cTest.value.Cmp(natives[0].(*big.Int)), "output %s should read back as the same value", cTest.name)
+	}
+}
+
+func TestArgsOptions_Validate(t *testing.T) {
+	require.NoError(t, (*ArgsOptions)(nil).Validate(), "nil options should be valid")
+	require.Error(t, (&ArgsOptions{Uint256Output: "bananas"}).Validate(), "unknown output format should be invalid")
+	require.Error(t, (&ArgsOptions{Uint256Output: "-1"}).Validate(), "negative decimals should be invalid")
+	require.Error(t, (&ArgsOptions{Uint256Units: map[string]int{"my token": 8}}).Validate(), "units should be letters only")
+}
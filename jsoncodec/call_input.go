@@ -0,0 +1,287 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package jsoncodec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"sort"
+	"strings"
+)
+
+var callInputFields = []string{"ContractName", "MethodName", "Arguments"}
+var argInputFields = []string{"Type", "Value"}
+
+// points errors in an input file to a json path and to its line and column
+type inputLocator struct {
+	filename string
+	input    []byte
+	offsets  map[string]int64 // json path -> offset of the key (object members) or value (array elements)
+}
+
+func newInputLocator(filename string, input []byte) *inputLocator {
+	return &inputLocator{filename: filename, input: input, offsets: inputPathOffsets(filename, input)}
+}
+
+func (l *inputLocator) errorf(path string, format string, args ...interface{}) error {
+	message := fmt.Sprintf(format, args...)
+	if path != "" {
+		message = path + ": " + message
+	}
+	return l.errorAt(path, message)
+}
+
+// paths without an offset of their own (eg. elements of a toml inline array) point to their closest parent
+func (l *inputLocator) errorAt(path string, message string) error {
+	for {
+		if offset, found := l.offsets[path]; found {
+			line, column := lineAndColumn(l.input, offset+1)
+			return errors.Errorf("%s:%d:%d: %s", l.filename, line, column, message)
+		}
+		if path == "" {
+			return errors.Errorf("%s: %s", l.filename, message)
+		}
+		path = parentJsonPath(path)
+	}
+}
+
+// decodes send-tx and run-query input, rejecting unknown top level fields, null values and malformed arguments
+func unmarshalCallInput(filename string, input []byte, v interface{}) error {
+	jsonBytes, err := inputToJson(filename, input)
+	if err != nil {
+		return err
+	}
+
+	var raw interface{}
+	dec := json.NewDecoder(bytes.NewReader(jsonBytes))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		if InputFormat(filename) != INPUT_FORMAT_JSON {
+			return convertedInputError(filename, input, jsonBytes, err)
+		}
+		return jsonInputError(filename, input, err)
+	}
+
+	if err := validateCallInput(newInputLocator(filename, input), raw); err != nil {
+		return err
+	}
+	return unmarshalCoercedInput(filename, input, v, coerceArgValues)
+}
+
+// yaml and toml have native numbers and bools (Value: 10), argument values are strings (bools are 1 or 0)
+func coerceArgValues(raw interface{}) {
+	fields, _ := raw.(map[string]interface{})
+	for key, value := range fields {
+		if knownField(callInputFields, key) != "Arguments" {
+			continue
+		}
+		args, _ := value.([]interface{})
+		for _, rawArg := range args {
+			arg, _ := rawArg.(map[string]interface{})
+			for argKey, argValue := range arg {
+				if knownField(argInputFields, argKey) == "Value" {
+					arg[argKey] = coerceArgValue(argValue)
+				}
+			}
+		}
+	}
+}
+
+func coerceArgValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case json.Number:
+		return value.String()
+	case bool:
+		if value {
+			return "1"
+		}
+		return "0"
+	case []interface{}:
+		for i, v := range value {
+			value[i] = coerceArgValue(v)
+		}
+		return value
+	default:
+		return value
+	}
+}
+
+func validateCallInput(l *inputLocator, raw interface{}) error {
+	if raw == nil {
+		return l.errorf("", "input is empty or null")
+	}
+	fields, ok := raw.(map[string]interface{})
+	if !ok {
+		return l.errorf("", "input should be an object with the fields %s", strings.Join(callInputFields, " "))
+	}
+
+	for _, key := range sortedKeys(fields) {
+		field := knownField(callInputFields, key)
+		if field == "" {
+			return l.errorf(key, "unknown field, known fields are %s", strings.Join(callInputFields, " "))
+		}
+		if fields[key] == nil {
+			return l.errorf(key, "value is null")
+		}
+		if field == "Arguments" {
+			if err := validateArgsInput(l, key, fields[key]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateArgsInput(l *inputLocator, path string, raw interface{}) error {
+	args, ok := raw.([]interface{})
+	if !ok {
+		return l.errorf(path, "should be an array of arguments")
+	}
+
+	for i, rawArg := range args {
+		argPath := fmt.Sprintf("%s[%d]", path, i)
+		if rawArg == nil {
+			return l.errorf(argPath, "argument is null")
+		}
+		arg, ok := rawArg.(map[string]interface{})
+		if !ok {
+			return l.errorf(argPath, "argument should be an object with the fields %s", strings.Join(argInputFields, " "))
+		}
+
+		found := make(map[string]bool)
+		for _, key := range sortedKeys(arg) {
+			field := knownField(argInputFields, key)
+			if field == "" {
+				return l.errorf(argPath+"."+key, "unknown field, known fields are %s", strings.Join(argInputFields, " "))
+			}
+			if arg[key] == nil {
+				return l.errorf(argPath+"."+key, "value is null")
+			}
+			found[field] = true
+		}
+		for _, field := range argInputFields {
+			if !found[field] {
+				return l.errorf(argPath, "%s is missing", field)
+			}
+		}
+	}
+	return nil
+}
+
+// ContractName can be given with -name, so it is only required after overrides are applied
+func RequireCallNames(filename string, contractName string, methodName string) error {
+	if contractName == "" {
+		return errors.Errorf("%s: ContractName is missing (set it in the file or with -name)", filename)
+	}
+	if methodName == "" {
+		return errors.Errorf("%s: MethodName is missing", filename)
+	}
+	return nil
+}
+
+// json field names are matched case insensitively like encoding/json does, returns "" for unknown fields
+func knownField(fields []string, key string) string {
+	for _, field := range fields {
+		if strings.EqualFold(field, key) {
+			return field
+		}
+	}
+	return ""
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// maps the json paths of the input (eg. Arguments[2].Value) to their offsets, invalid json yields the offsets read so far
+func jsonPathOffsets(input []byte) map[string]int64 {
+	type container struct {
+		path      string
+		isArray   bool
+		index     int
+		key       string
+		expectKey bool
+	}
+
+	res := make(map[string]int64)
+	var stack []*container
+	dec := json.NewDecoder(bytes.NewReader(input))
+	for {
+		offset := skipJsonSeparators(input, dec.InputOffset())
+		token, err := dec.Token()
+		if err != nil {
+			return res
+		}
+
+		if delim, ok := token.(json.Delim); ok && (delim == '}' || delim == ']') {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		path := ""
+		if len(stack) > 0 {
+			top := stack[len(stack)-1]
+			if top.isArray {
+				path = fmt.Sprintf("%s[%d]", top.path, top.index)
+				top.index++
+				res[path] = offset
+			} else if top.expectKey {
+				top.key, _ = token.(string)
+				top.expectKey = false
+				res[joinJsonPath(top.path, top.key)] = offset
+				continue
+			} else {
+				path = joinJsonPath(top.path, top.key)
+				top.expectKey = true
+			}
+		}
+
+		if delim, ok := token.(json.Delim); ok {
+			stack = append(stack, &container{path: path, isArray: delim == '[', expectKey: delim == '{'})
+		}
+	}
+}
+
+func joinJsonPath(parent string, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+func parentJsonPath(path string) string {
+	if i := strings.LastIndexAny(path, ".["); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+// the innermost path read before offset, which for a decode error is the path of the offending value
+func jsonPathAt(offsets map[string]int64, offset int64) string {
+	res, resOffset := "", int64(-1)
+	for path, pathOffset := range offsets {
+		if pathOffset < offset && pathOffset > resOffset {
+			res, resOffset = path, pathOffset
+		}
+	}
+	return res
+}
+
+// the decoder offset is right after the previous token, before any whitespace, colon or comma
+func skipJsonSeparators(input []byte, offset int64) int64 {
+	for offset < int64(len(input)) && strings.IndexByte(" \t\r\n:,", input[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
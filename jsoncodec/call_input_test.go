@@ -0,0 +1,56 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package jsoncodec
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestUnmarshalSendTx_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		input    string
+		expected string
+	}{
+		{"Null", "tx.json", "null", "tx.json: input is empty or null"},
+		{"NotObject", "tx.json", "[]", "tx.json: input should be an object"},
+		{"UnknownField", "tx.json", "{\n  \"ContractName\": \"MyToken\",\n  \"Method\": \"transfer\"\n}", "tx.json:3:3: Method: unknown field"},
+		{"NullField", "tx.json", "{\n  \"ContractName\": null\n}", "tx.json:2:3: ContractName: value is null"},
+		{"ArgumentsNotArray", "tx.json", "{\"Arguments\": {}}", "tx.json:1:2: Arguments: should be an array"},
+		{"NullArgument", "tx.json", "{\"Arguments\": [\n  {\"Type\": \"uint32\", \"Value\": \"1\"},\n  null\n]}", "tx.json:3:3: Arguments[1]: argument is null"},
+		{"MissingValue", "tx.json", "{\"Arguments\": [\n  {\"Type\": \"uint32\", \"Value\": \"1\"},\n  {\"Type\": \"uint32\", \"Value\": \"2\"},\n  {\"Type\": \"uint32\"}\n]}", "tx.json:4:3: Arguments[2]: Value is missing"},
+		{"NullValue", "tx.json", "{\"Arguments\": [\n  {\"Type\": \"uint32\", \"Value\": null}\n]}", "tx.json:2:22: Arguments[0].Value: value is null"},
+		{"UnknownArgumentField", "tx.json", "{\"Arguments\": [{\"Type\": \"uint32\", \"Val\": \"1\"}]}", "tx.json:1:35: Arguments[0].Val: unknown field"},
+		{"Yaml", "tx.yaml", "Arguments:\n  - Type: uint32\n", "tx.yaml:2:5: Arguments[0]: Value is missing"},
+		{"YamlUnknownArgumentField", "tx.yaml", "Arguments:\n  - Type: uint32\n    Val: 1\n", "tx.yaml:3:5: Arguments[0].Val: unknown field"},
+		{"TomlArrayTables", "tx.toml", "[[Arguments]]\nType = \"uint32\"\nValue = 1\n\n[[Arguments]]\nType = \"uint32\"\nVal = 2\n", "tx.toml:7:1: Arguments[1].Val: unknown field"},
+		{"TomlInlineTables", "tx.toml", "Arguments = [\n  {Type = \"uint32\", Value = \"{\"},\n  {Type = \"uint32\", Val = 2},\n]\n", "tx.toml:3:21: Arguments[1].Val: unknown field"},
+		{"TomlUnknownField", "tx.toml", "Method = \"transfer\"\n", "tx.toml:1:1: Method: unknown field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := UnmarshalSendTx(tt.filename, []byte(tt.input))
+			require.Error(t, err, "invalid input should fail")
+			require.Contains(t, err.Error(), tt.expected, "error should point to the file, path and position")
+		})
+	}
+}
+
+func TestUnmarshalRead_CaseInsensitiveFields(t *testing.T) {
+	read, err := UnmarshalRead("query.json", []byte(`{"contractName": "MyToken", "methodName": "balance", "arguments": [{"type": "uint32", "value": "1"}]}`))
+	require.NoError(t, err, "field names should be matched like encoding/json does")
+	require.Equal(t, "MyToken", read.ContractName)
+	require.Equal(t, "1", read.Arguments[0].Value)
+}
+
+func TestRequireCallNames(t *testing.T) {
+	require.NoError(t, RequireCallNames("tx.json", "MyToken", "transfer"))
+	require.EqualError(t, RequireCallNames("tx.json", "", "transfer"), "tx.json: ContractName is missing (set it in the file or with -name)")
+	require.EqualError(t, RequireCallNames("tx.json", "MyToken", ""), "tx.json: MethodName is missing")
+}
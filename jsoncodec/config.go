@@ -6,20 +6,61 @@
 
 package jsoncodec
 
-import "encoding/json"
-
 type ConfFile struct {
-	Environments map[string]*ConfEnv
+	Environments       map[string]*ConfEnv
+	DefaultEnvironment string `json:",omitempty"` // used when neither -env nor GAMMA_ENV are given
 }
 
 type ConfEnv struct {
 	VirtualChain uint32
 	Endpoints    []string
 	Experimental bool
+	NetworkType  string     `json:",omitempty"` // main or test, defaults to test
+	Http         *ConfHttp  `json:",omitempty"`
+	Retry        *ConfRetry `json:",omitempty"`
+
+	// defaults of the environment for options not given explicitly, paths are relative to the config file
+	Signer          string            `json:",omitempty"` // id of the signing key, instead of -signer
+	Keys            string            `json:",omitempty"` // path of the test keys file, instead of -keys
+	PrismPort       int               `json:",omitempty"` // listening port for Prism, instead of -prismPort
+	ContractAliases map[string]string `json:",omitempty"` // contract names used in input files and -name -> deployed contract names
+
+	// docker images run by start-local, the official ones on Docker Hub if omitted
+	GammaImage *ConfImage `json:",omitempty"`
+	PrismImage *ConfImage `json:",omitempty"`
+}
+
+// a docker image and the registry its tags are listed from
+type ConfImage struct {
+	Repository string `json:",omitempty"` // image repository, may start with a registry host (eg. registry.example.com/team/gamma)
+	Tag        string `json:",omitempty"` // pinned image tag, the latest version in the registry if omitted
+	Registry   string `json:",omitempty"` // url of the Docker Registry v2 API, derived from Repository if omitted
+	Username   string `json:",omitempty"` // credentials for listing tags in the registry, values may contain ${ENV_VAR}
+	Password   string `json:",omitempty"`
+}
+
+// http client settings for reaching the nodes of an environment, paths are relative to the config file
+type ConfHttp struct {
+	Timeout    string            `json:",omitempty"` // request timeout as a duration (eg. "30s")
+	CaBundle   string            `json:",omitempty"` // pem file of CA certificates trusted in addition to the system ones
+	ClientCert string            `json:",omitempty"` // pem file of the client certificate for mutual tls
+	ClientKey  string            `json:",omitempty"` // pem file of the client certificate private key
+	SkipVerify bool              `json:",omitempty"` // do not verify server certificates (self-signed dev nodes only)
+	Proxy      string            `json:",omitempty"` // proxy url, HTTP_PROXY and HTTPS_PROXY are used if omitted
+	Headers    map[string]string `json:",omitempty"` // static headers added to every request, values may contain ${ENV_VAR}
+}
+
+// retry policy for requests that fail to reach the nodes of an environment, omitted fields use the defaults
+// without it requests are not retried, with it (even empty) they are attempted 3 times
+type ConfRetry struct {
+	MaxAttempts int      `json:",omitempty"` // total attempts per request including the first, 1 disables retries
+	Backoff     string   `json:",omitempty"` // delay before the first retry as a duration, doubled on every retry
+	MaxBackoff  string   `json:",omitempty"` // upper bound of the delay between retries
+	Jitter      *float64 `json:",omitempty"` // fraction of the delay that is randomized, between 0 and 1
 }
 
-func UnmarshalConfFile(bytes []byte) (*ConfFile, error) {
+func UnmarshalConfFile(filename string, bytes []byte) (*ConfFile, error) {
 	var confFile *ConfFile
-	err := json.Unmarshal(bytes, &confFile)
+	err := unmarshalInput(filename, bytes, &confFile)
 	return confFile, err
 }
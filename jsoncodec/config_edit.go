@@ -0,0 +1,212 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package jsoncodec
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// a config file edited as generic values, so fields unknown to this version of gamma-cli survive a rewrite
+type EditableConfFile struct {
+	values map[string]interface{}
+}
+
+func NewEditableConfFile() *EditableConfFile {
+	return &EditableConfFile{values: map[string]interface{}{
+		"Environments": map[string]interface{}{},
+	}}
+}
+
+func UnmarshalEditableConfFile(filename string, bytes []byte) (*EditableConfFile, error) {
+	if _, err := UnmarshalConfFile(filename, bytes); err != nil {
+		return nil, err
+	}
+
+	jsonBytes, err := inputToJson(filename, bytes)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(jsonBytes, &values); err != nil {
+		return nil, err
+	}
+
+	canonical, _ := canonicalConfKeys(integralNumbers(values), reflect.TypeOf(ConfFile{})).(map[string]interface{})
+	if canonical == nil {
+		canonical = make(map[string]interface{})
+	}
+	return &EditableConfFile{values: canonical}, nil
+}
+
+// written in the format of the file extension, like the inputs it is read from
+func MarshalEditableConfFile(filename string, f *EditableConfFile) ([]byte, error) {
+	switch InputFormat(filename) {
+	case INPUT_FORMAT_YAML:
+		return yaml.Marshal(f.values)
+	case INPUT_FORMAT_TOML:
+		var buf bytes.Buffer
+		err := toml.NewEncoder(&buf).Encode(f.values)
+		return buf.Bytes(), err
+	default:
+		return json.MarshalIndent(f.values, "", "  ")
+	}
+}
+
+func (f *EditableConfFile) HasEnvironment(id string) bool {
+	_, found := f.environments()[id]
+	return found
+}
+
+// values are the fields of ConfEnv to set, fields of an existing environment that are not given are kept
+func (f *EditableConfFile) SetEnvironment(id string, values map[string]interface{}) {
+	envs := f.environments()
+	env, _ := envs[id].(map[string]interface{})
+	if env == nil {
+		env = make(map[string]interface{})
+	}
+	canonical, _ := canonicalConfKeys(values, reflect.TypeOf(ConfEnv{})).(map[string]interface{})
+	for key, value := range canonical {
+		env[key] = value
+	}
+	envs[id] = env
+}
+
+func (f *EditableConfFile) RemoveEnvironment(id string) bool {
+	envs := f.environments()
+	if _, found := envs[id]; !found {
+		return false
+	}
+	delete(envs, id)
+	if f.values["DefaultEnvironment"] == id {
+		delete(f.values, "DefaultEnvironment")
+	}
+	return true
+}
+
+func (f *EditableConfFile) SetDefaultEnvironment(id string) {
+	f.values["DefaultEnvironment"] = id
+}
+
+func (f *EditableConfFile) environments() map[string]interface{} {
+	envs, _ := f.values["Environments"].(map[string]interface{})
+	if envs == nil {
+		envs = make(map[string]interface{})
+		f.values["Environments"] = envs
+	}
+	return envs
+}
+
+// warnings about fields this version of gamma-cli does not know, which are kept but ignored (often typos like Endpoint)
+func UnknownConfFields(filename string, bytes []byte) []error {
+	jsonBytes, err := inputToJson(filename, bytes)
+	if err != nil {
+		return nil
+	}
+	var values interface{}
+	if err := json.Unmarshal(jsonBytes, &values); err != nil {
+		return nil
+	}
+
+	var res []error
+	collectUnknownConfFields(newInputLocator(filename, bytes), "", values, reflect.TypeOf(ConfFile{}), &res)
+	return res
+}
+
+func collectUnknownConfFields(l *inputLocator, path string, value interface{}, t reflect.Type, res *[]error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for _, key := range sortedKeys(m) {
+		keyPath := joinJsonPath(path, key)
+		switch t.Kind() {
+		case reflect.Struct:
+			field, found := findFieldFold(t, key)
+			if !found {
+				if similar := similarFieldName(t, key); similar != "" {
+					*res = append(*res, l.errorf(keyPath, "unknown field '%s' is ignored, did you mean '%s'?", key, similar))
+				} else {
+					*res = append(*res, l.errorf(keyPath, "unknown field '%s' is ignored", key))
+				}
+				continue
+			}
+			collectUnknownConfFields(l, keyPath, m[key], field.Type, res)
+		case reflect.Map:
+			collectUnknownConfFields(l, keyPath, m[key], t.Elem(), res)
+		}
+	}
+}
+
+// the field of struct type t within an edit distance of 2 from name, ignoring case
+func similarFieldName(t reflect.Type, name string) string {
+	for i := 0; i < t.NumField(); i++ {
+		if editDistance(strings.ToLower(t.Field(i).Name), strings.ToLower(name)) <= 2 {
+			return t.Field(i).Name
+		}
+	}
+	return ""
+}
+
+func editDistance(a string, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(prev[j]+1, minInt(current[j-1]+1, prev[j-1]+cost))
+		}
+		prev = current
+	}
+	return prev[len(b)]
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// json decodes all numbers as float64, which toml would write back as 42.0
+func integralNumbers(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, v := range value {
+			value[key] = integralNumbers(v)
+		}
+		return value
+	case []interface{}:
+		for i, v := range value {
+			value[i] = integralNumbers(v)
+		}
+		return value
+	case float64:
+		if value == math.Trunc(value) && math.Abs(value) < 1<<53 {
+			return int64(value)
+		}
+		return value
+	default:
+		return value
+	}
+}
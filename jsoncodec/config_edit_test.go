@@ -0,0 +1,80 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package jsoncodec
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestEditableConfFile_PreservesUnknownFields(t *testing.T) {
+	tests := []struct {
+		filename string
+		input    string
+	}{
+		{"orbs-gamma-config.json", `{"Comment": "team config", "environments": {"staging": {"VirtualChain": 1000, "Endpoints": ["http://a"], "Owner": "ops"}}}`},
+		{"orbs-gamma-config.yaml", "Comment: team config\nenvironments:\n  staging:\n    VirtualChain: 1000\n    Endpoints: [\"http://a\"]\n    Owner: ops\n"},
+		{"orbs-gamma-config.toml", "Comment = \"team config\"\n[environments.staging]\nVirtualChain = 1000\nEndpoints = [\"http://a\"]\nOwner = \"ops\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			confFile, err := UnmarshalEditableConfFile(tt.filename, []byte(tt.input))
+			require.NoError(t, err)
+
+			require.True(t, confFile.HasEnvironment("staging"), "field names should be matched case insensitively")
+			confFile.SetEnvironment("testnet", map[string]interface{}{"VirtualChain": uint(1100), "Endpoints": []interface{}{"http://b"}})
+			confFile.SetDefaultEnvironment("testnet")
+
+			output, err := MarshalEditableConfFile(tt.filename, confFile)
+			require.NoError(t, err)
+
+			res, err := UnmarshalConfFile(tt.filename, output)
+			require.NoError(t, err, "output should be a valid config file:\n%s", output)
+			require.Equal(t, "testnet", res.DefaultEnvironment)
+			require.EqualValues(t, 1000, res.Environments["staging"].VirtualChain)
+			require.EqualValues(t, 1100, res.Environments["testnet"].VirtualChain)
+			require.Equal(t, []string{"http://b"}, res.Environments["testnet"].Endpoints)
+			require.Contains(t, string(output), "team config", "unknown top level fields should be kept")
+			require.Contains(t, string(output), "ops", "unknown environment fields should be kept")
+			require.NotContains(t, string(output), "1000.0", "integers should not be written as floats")
+		})
+	}
+}
+
+func TestEditableConfFile_RemoveEnvironment(t *testing.T) {
+	confFile, err := UnmarshalEditableConfFile("orbs-gamma-config.json", []byte(`{"DefaultEnvironment": "staging", "Environments": {"staging": {}, "testnet": {}}}`))
+	require.NoError(t, err)
+
+	require.True(t, confFile.RemoveEnvironment("staging"))
+	require.False(t, confFile.RemoveEnvironment("staging"), "removed environment should not be found")
+
+	output, err := MarshalEditableConfFile("orbs-gamma-config.json", confFile)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"Environments": {"testnet": {}}}`, string(output), "default environment should be cleared with its environment")
+}
+
+func TestUnknownConfFields(t *testing.T) {
+	input := `{
+  "Environments": {
+    "testnet": {
+      "VirtualChain": 1000,
+      "Endpoint": ["http://a"],
+      "Http": {"timeout": "5s", "Header": {"X-Token": "1"}}
+    }
+  },
+  "Owner": "ops"
+}`
+	var warnings []string
+	for _, err := range UnknownConfFields("orbs-gamma-config.json", []byte(input)) {
+		warnings = append(warnings, err.Error())
+	}
+	require.Equal(t, []string{
+		"orbs-gamma-config.json:5:7: Environments.testnet.Endpoint: unknown field 'Endpoint' is ignored, did you mean 'Endpoints'?",
+		"orbs-gamma-config.json:6:33: Environments.testnet.Http.Header: unknown field 'Header' is ignored, did you mean 'Headers'?",
+		"orbs-gamma-config.json:9:3: Owner: unknown field 'Owner' is ignored",
+	}, warnings)
+}
@@ -0,0 +1,238 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package jsoncodec
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// fields of ConfEnv and ConfHttp holding paths, which are relative to the config file declaring them
+var confEnvPathFields = []string{"Keys"}
+var confHttpPathFields = []string{"CaBundle", "ClientCert", "ClientKey"}
+
+// the values of one config source (a file, built-in defaults or environment variables)
+type ConfLayer struct {
+	Source string
+	values map[string]interface{}
+}
+
+// the config merged from all layers, remembering which layer set every value
+type ResolvedConf struct {
+	*ConfFile
+	values  map[string]interface{}
+	sources map[string]string // dotted path of every set value -> source of the layer that set it
+}
+
+type ResolvedValue struct {
+	Field  string
+	Value  string // json encoded
+	Source string
+}
+
+func UnmarshalConfLayer(filename string, bytes []byte) (*ConfLayer, error) {
+	if _, err := UnmarshalConfFile(filename, bytes); err != nil {
+		return nil, err
+	}
+
+	jsonBytes, err := inputToJson(filename, bytes)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(jsonBytes, &values); err != nil {
+		return nil, err
+	}
+
+	layer := NewConfLayer(filename, values)
+	if dir, err := filepath.Abs(filepath.Dir(filename)); err == nil {
+		layer.resolvePaths(dir)
+	}
+	return layer, nil
+}
+
+// values of a single environment, for layers that are not files
+func NewConfEnvLayer(source string, envId string, values map[string]interface{}) *ConfLayer {
+	return NewConfLayer(source, map[string]interface{}{
+		"Environments": map[string]interface{}{envId: values},
+	})
+}
+
+// field names are matched case insensitively like json decoding does, so layers spelling them differently still merge
+func NewConfLayer(source string, values map[string]interface{}) *ConfLayer {
+	canonical, _ := canonicalConfKeys(values, reflect.TypeOf(ConfFile{})).(map[string]interface{})
+	return &ConfLayer{Source: source, values: canonical}
+}
+
+// later layers override earlier ones field by field, objects are merged and all other values (including arrays) replaced
+func MergeConfLayers(layers ...*ConfLayer) (*ResolvedConf, error) {
+	res := &ResolvedConf{
+		values:  make(map[string]interface{}),
+		sources: make(map[string]string),
+	}
+	for _, layer := range layers {
+		res.merge(res.values, layer.values, nil, layer.Source)
+	}
+
+	bytes, err := json.Marshal(res.values)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(bytes, &res.ConfFile); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// the source of the value at the dotted path, the sources of the values inside it when it is an object
+// or the source of the closest enclosing value that was set
+func (c *ResolvedConf) Source(path string) string {
+	if source, found := c.sources[path]; found {
+		return source
+	}
+	if sources := c.nestedSources(path); len(sources) > 0 {
+		return strings.Join(sources, ", ")
+	}
+	for {
+		i := strings.LastIndex(path, ".")
+		if i == -1 {
+			return ""
+		}
+		path = path[:i]
+		if source, found := c.sources[path]; found {
+			return source
+		}
+	}
+}
+
+// every value set for the environment, sorted by field
+func (c *ResolvedConf) EnvValues(envId string) []*ResolvedValue {
+	prefix := "Environments." + envId + "."
+	var res []*ResolvedValue
+	for path, source := range c.sources {
+		if !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		value, _ := json.Marshal(c.valueAt(strings.Split(path, ".")))
+		res = append(res, &ResolvedValue{Field: strings.TrimPrefix(path, prefix), Value: string(value), Source: source})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Field < res[j].Field
+	})
+	return res
+}
+
+func (c *ResolvedConf) nestedSources(path string) []string {
+	found := make(map[string]bool)
+	var res []string
+	for p, source := range c.sources {
+		if strings.HasPrefix(p, path+".") && !found[source] {
+			found[source] = true
+			res = append(res, source)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+func (c *ResolvedConf) merge(dst map[string]interface{}, src map[string]interface{}, path []string, source string) {
+	for key, value := range src {
+		keyPath := append(path[:len(path):len(path)], key)
+		if srcMap, ok := value.(map[string]interface{}); ok {
+			dstMap, ok := dst[key].(map[string]interface{})
+			if !ok {
+				c.clearSources(keyPath)
+				dstMap = make(map[string]interface{})
+				dst[key] = dstMap
+			}
+			c.merge(dstMap, srcMap, keyPath, source)
+			continue
+		}
+
+		c.clearSources(keyPath)
+		dst[key] = value
+		c.sources[strings.Join(keyPath, ".")] = source
+	}
+}
+
+func (c *ResolvedConf) clearSources(path []string) {
+	prefix := strings.Join(path, ".")
+	for p := range c.sources {
+		if p == prefix || strings.HasPrefix(p, prefix+".") {
+			delete(c.sources, p)
+		}
+	}
+}
+
+func (c *ResolvedConf) valueAt(path []string) interface{} {
+	var value interface{} = c.values
+	for _, key := range path {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[key]
+	}
+	return value
+}
+
+func (l *ConfLayer) resolvePaths(dir string) {
+	envs, _ := l.values["Environments"].(map[string]interface{})
+	for _, env := range envs {
+		env, _ := env.(map[string]interface{})
+		resolveRelativePaths(env, confEnvPathFields, dir)
+		http, _ := env["Http"].(map[string]interface{})
+		resolveRelativePaths(http, confHttpPathFields, dir)
+	}
+}
+
+func resolveRelativePaths(values map[string]interface{}, fields []string, dir string) {
+	for _, field := range fields {
+		if path, ok := values[field].(string); ok && path != "" && !filepath.IsAbs(path) {
+			values[field] = filepath.Join(dir, path)
+		}
+	}
+}
+
+func canonicalConfKeys(value interface{}, t reflect.Type) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+
+	res := make(map[string]interface{})
+	for key, v := range m {
+		switch t.Kind() {
+		case reflect.Struct:
+			if field, found := findFieldFold(t, key); found {
+				res[field.Name] = canonicalConfKeys(v, field.Type)
+			} else {
+				res[key] = v
+			}
+		case reflect.Map:
+			res[key] = canonicalConfKeys(v, t.Elem())
+		default:
+			res[key] = v
+		}
+	}
+	return res
+}
+
+func findFieldFold(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if strings.EqualFold(t.Field(i).Name, name) {
+			return t.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
@@ -0,0 +1,74 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package jsoncodec
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMergeConfLayers(t *testing.T) {
+	global, err := UnmarshalConfLayer("/home/user/.orbs/orbs-gamma-config.json", []byte(`{
+  "Environments": {
+    "testnet": {"virtualChain": 1000, "Endpoints": ["http://a", "http://b"], "Keys": "keys/testnet.json", "Http": {"CaBundle": "ca.pem", "Headers": {"X-Token": "1"}}},
+    "mainnet": {"VirtualChain": 1100, "Endpoints": ["http://main"]}
+  }
+}`))
+	require.NoError(t, err)
+
+	project, err := UnmarshalConfLayer("/work/orbs-gamma-config.yaml", []byte(`
+Environments:
+  testnet:
+    endpoints: ["http://c"]
+    http:
+      headers:
+        x-token: "2"
+`))
+	require.NoError(t, err)
+
+	override := NewConfEnvLayer("GAMMA_VCHAIN", "testnet", map[string]interface{}{"VirtualChain": 7})
+
+	conf, err := MergeConfLayers(global, project, override)
+	require.NoError(t, err)
+
+	testnet := conf.Environments["testnet"]
+	require.EqualValues(t, 7, testnet.VirtualChain)
+	require.Equal(t, []string{"http://c"}, testnet.Endpoints, "arrays should be replaced")
+	require.Equal(t, "/home/user/.orbs/ca.pem", testnet.Http.CaBundle, "paths should be relative to the declaring file")
+	require.Equal(t, "/home/user/.orbs/keys/testnet.json", testnet.Keys)
+	require.Equal(t, map[string]string{"X-Token": "1", "x-token": "2"}, testnet.Http.Headers, "header names are map keys and should not be folded")
+	require.EqualValues(t, 1100, conf.Environments["mainnet"].VirtualChain)
+
+	require.Equal(t, []*ResolvedValue{
+		{"Endpoints", `["http://c"]`, "/work/orbs-gamma-config.yaml"},
+		{"Http.CaBundle", `"/home/user/.orbs/ca.pem"`, "/home/user/.orbs/orbs-gamma-config.json"},
+		{"Http.Headers.X-Token", `"1"`, "/home/user/.orbs/orbs-gamma-config.json"},
+		{"Http.Headers.x-token", `"2"`, "/work/orbs-gamma-config.yaml"},
+		{"Keys", `"/home/user/.orbs/keys/testnet.json"`, "/home/user/.orbs/orbs-gamma-config.json"},
+		{"VirtualChain", `7`, "GAMMA_VCHAIN"},
+	}, conf.EnvValues("testnet"))
+	require.Equal(t, "/home/user/.orbs/orbs-gamma-config.json, /work/orbs-gamma-config.yaml", conf.Source("Environments.testnet.Http"), "an object should report the sources of its values")
+	require.Equal(t, "GAMMA_VCHAIN", conf.Source("Environments.testnet.VirtualChain"))
+	require.Equal(t, "/home/user/.orbs/orbs-gamma-config.json", conf.Source("Environments.mainnet.Endpoints"))
+	require.Equal(t, "", conf.Source("Environments.mainnet.Http"))
+}
+
+func TestMergeConfLayers_ReplacedObject(t *testing.T) {
+	conf, err := MergeConfLayers(
+		NewConfEnvLayer("a", "testnet", map[string]interface{}{"Http": map[string]interface{}{"Timeout": "5s"}}),
+		NewConfEnvLayer("b", "testnet", map[string]interface{}{"Http": nil}),
+	)
+	require.NoError(t, err)
+	require.Nil(t, conf.Environments["testnet"].Http)
+	require.Equal(t, []*ResolvedValue{{"Http", "null", "b"}}, conf.EnvValues("testnet"), "values of a replaced object should not be reported")
+	require.Equal(t, "b", conf.Source("Environments.testnet.Http.Timeout"), "a value inside a replaced object should report the replacing source")
+}
+
+func TestUnmarshalConfLayer_Invalid(t *testing.T) {
+	_, err := UnmarshalConfLayer("orbs-gamma-config.json", []byte(`{"Environments": {"testnet": {"VirtualChain": "x"}}}`))
+	require.Error(t, err, "invalid files should fail before merging")
+}
@@ -0,0 +1,57 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package jsoncodec
+
+import "encoding/json"
+
+// records deployed contracts per environment id
+type DeployLock struct {
+	Environments map[string]*DeployLockEnv
+}
+
+type DeployLockEnv struct {
+	Contracts map[string]*DeployLockContract
+	Versions  map[string]int    `json:",omitempty"` // logical contract name -> latest version deployed with -versioned
+	Aliases   map[string]string `json:",omitempty"` // logical contract name -> deployed contract name
+}
+
+type DeployLockContract struct {
+	Name             string
+	SourceHash       string // hex sha256 of the deployed sources, empty for contracts deployed without this lockfile
+	TxId             string
+	BlockHeight      string
+	InitCallsPending int `json:",omitempty"` // the last init calls of the manifest not sent yet, deploy-all resumes them
+}
+
+func UnmarshalDeployLock(bytes []byte) (*DeployLock, error) {
+	var lock *DeployLock
+	err := json.Unmarshal(bytes, &lock)
+	return lock, err
+}
+
+func MarshalDeployLock(lock *DeployLock) ([]byte, error) {
+	return json.MarshalIndent(lock, "", "  ")
+}
+
+func (lock *DeployLock) Env(env string) *DeployLockEnv {
+	if lock.Environments == nil {
+		lock.Environments = make(map[string]*DeployLockEnv)
+	}
+	if lock.Environments[env] == nil {
+		lock.Environments[env] = &DeployLockEnv{}
+	}
+	if lock.Environments[env].Contracts == nil {
+		lock.Environments[env].Contracts = make(map[string]*DeployLockContract)
+	}
+	if lock.Environments[env].Versions == nil {
+		lock.Environments[env].Versions = make(map[string]int)
+	}
+	if lock.Environments[env].Aliases == nil {
+		lock.Environments[env].Aliases = make(map[string]string)
+	}
+	return lock.Environments[env]
+}
@@ -0,0 +1,36 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package jsoncodec
+
+import (
+	"encoding/json"
+	"github.com/orbs-network/orbs-client-sdk-go/codec"
+)
+
+// the same request answered by every endpoint of an environment
+type EndpointsReport struct {
+	Consistent    bool
+	HeightsDiffer bool `json:",omitempty"` // queries only, nodes answered from different block heights which does not make them divergent
+	Nodes         []*EndpointResponse
+}
+
+type EndpointResponse struct {
+	Endpoint          string
+	Error             string                  `json:",omitempty"`
+	RequestStatus     codec.RequestStatus     `json:",omitempty"`
+	ExecutionResult   codec.ExecutionResult   `json:",omitempty"`
+	TransactionStatus codec.TransactionStatus `json:",omitempty"`
+	BlockHeight       string                  `json:",omitempty"`
+	OutputArguments   []*Arg                  `json:",omitempty"`
+	OutputEvents      []*Event                `json:",omitempty"`
+	Divergent         []string                `json:",omitempty"` // fields that differ from the majority of nodes
+	HeightDiffers     bool                    `json:",omitempty"` // queries only, BlockHeight differs from the majority of nodes
+}
+
+func MarshalEndpointsReport(report *EndpointsReport) ([]byte, error) {
+	return json.MarshalIndent(report, "", "  ")
+}
@@ -17,10 +17,10 @@ type Event struct {
 	Arguments    []*Arg
 }
 
-func MarshalEvents(events []*codec.Event) ([]*Event, error) {
+func MarshalEvents(events []*codec.Event, opts *ArgsOptions) ([]*Event, error) {
 	res := []*Event{}
 	for i, event := range events {
-		eventArgs, err := MarshalArgs(event.Arguments)
+		eventArgs, err := MarshalArgs(event.Arguments, opts)
 		if err != nil {
 			return nil, errors.Errorf("Event %d arguments marshaling failed with %s \n", i+1, err.Error())
 		}
@@ -0,0 +1,235 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package jsoncodec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type gammaConfigKind int
+
+const (
+	GAMMA_CONFIG_UINT gammaConfigKind = iota
+	GAMMA_CONFIG_BOOL
+	GAMMA_CONFIG_STRING
+	GAMMA_CONFIG_DURATION
+	GAMMA_CONFIG_HEX
+	GAMMA_CONFIG_HEX_ARRAY
+)
+
+var gammaConfigKindNames = map[gammaConfigKind]string{
+	GAMMA_CONFIG_UINT:      "a non negative integer",
+	GAMMA_CONFIG_BOOL:      "true or false",
+	GAMMA_CONFIG_STRING:    "a string",
+	GAMMA_CONFIG_DURATION:  "a duration string (eg. \"500ms\")",
+	GAMMA_CONFIG_HEX:       "a hex string",
+	GAMMA_CONFIG_HEX_ARRAY: "an array of hex strings",
+}
+
+// config keys of Gamma server known to gamma-cli, keys not listed are passed to the server as is
+// taken from the keys of orbs-network-go config/config.go (lowercased and dash separated as in its json config) as of v1.3.12
+var gammaConfigKeys = map[string]gammaConfigKind{
+	"virtual-chain-id":                   GAMMA_CONFIG_UINT,
+	"node-address":                       GAMMA_CONFIG_HEX,
+	"node-private-key":                   GAMMA_CONFIG_HEX,
+	"genesis-validator-addresses":        GAMMA_CONFIG_HEX_ARRAY,
+	"http-address":                       GAMMA_CONFIG_STRING,
+	"profiling":                          GAMMA_CONFIG_BOOL,
+	"active-consensus-algo":              GAMMA_CONFIG_UINT,
+	"benchmark-consensus-retry-interval": GAMMA_CONFIG_DURATION,
+	"benchmark-consensus-required-quorum-percentage":  GAMMA_CONFIG_UINT,
+	"lean-helix-consensus-round-timeout-interval":     GAMMA_CONFIG_DURATION,
+	"lean-helix-consensus-minimum-committee-size":     GAMMA_CONFIG_UINT,
+	"lean-helix-show-debug":                           GAMMA_CONFIG_BOOL,
+	"consensus-context-maximum-transactions-in-block": GAMMA_CONFIG_UINT,
+	"transaction-pool-time-between-empty-blocks":      GAMMA_CONFIG_DURATION,
+	"transaction-pool-pending-pool-size-in-bytes":     GAMMA_CONFIG_UINT,
+	"transaction-pool-propagation-batch-size":         GAMMA_CONFIG_UINT,
+	"transaction-pool-propagation-batching-timeout":   GAMMA_CONFIG_DURATION,
+	"transaction-pool-future-timestamp-grace-timeout": GAMMA_CONFIG_DURATION,
+	"transaction-expiration-window":                   GAMMA_CONFIG_DURATION,
+	"block-sync-num-blocks-in-batch":                  GAMMA_CONFIG_UINT,
+	"block-sync-no-commit-interval":                   GAMMA_CONFIG_DURATION,
+	"block-sync-collect-response-timeout":             GAMMA_CONFIG_DURATION,
+	"block-tracker-grace-distance":                    GAMMA_CONFIG_UINT,
+	"block-tracker-grace-timeout":                     GAMMA_CONFIG_DURATION,
+	"public-api-send-transaction-timeout":             GAMMA_CONFIG_DURATION,
+	"public-api-node-sync-warning-time":               GAMMA_CONFIG_DURATION,
+	"processor-sanitize-deployed-contracts":           GAMMA_CONFIG_BOOL,
+	"processor-perform-warm-up-compilation":           GAMMA_CONFIG_BOOL,
+	"processor-artifact-path":                         GAMMA_CONFIG_STRING,
+	"experimental-external-processor-plugin-path":     GAMMA_CONFIG_STRING,
+	"ethereum-endpoint":                               GAMMA_CONFIG_STRING,
+	"ethereum-finality-time-component":                GAMMA_CONFIG_DURATION,
+	"ethereum-finality-blocks-component":              GAMMA_CONFIG_UINT,
+	"management-file-path":                            GAMMA_CONFIG_STRING,
+	"management-update-interval":                      GAMMA_CONFIG_DURATION,
+	"logger-full-log":                                 GAMMA_CONFIG_BOOL,
+	"logger-http-endpoint":                            GAMMA_CONFIG_STRING,
+	"logger-bulk-size":                                GAMMA_CONFIG_UINT,
+	"logger-file-truncation-interval":                 GAMMA_CONFIG_DURATION,
+}
+
+// a validated -override-config, numbers keep their original text
+type GammaConfigOverride struct {
+	values map[string]interface{}
+}
+
+// errors for invalid json, values of the wrong type and unknown keys, naming a known key when they look like a typo of it
+// with allowUnknownKeys (for keys of newer Gamma servers) unknown keys are returned as warnings and passed to the server as is
+func UnmarshalGammaConfigOverride(filename string, input []byte, allowUnknownKeys bool) (*GammaConfigOverride, []error, error) {
+	l := &inputLocator{filename: filename, input: input, offsets: jsonPathOffsets(input)}
+
+	var values map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(input))
+	dec.UseNumber()
+	if err := dec.Decode(&values); err != nil {
+		return nil, nil, jsonInputError(filename, input, err)
+	}
+	if values == nil {
+		return nil, nil, l.errorf("", "override config should be a json object")
+	}
+	if dec.More() {
+		return nil, nil, l.errorf("", "unexpected data after the json object")
+	}
+
+	var warnings []error
+	for _, key := range sortedKeys(values) {
+		kind, found := gammaConfigKeys[key]
+		if !found {
+			hint := ""
+			if similar := similarGammaConfigKey(key); similar != "" {
+				hint = fmt.Sprintf(", did you mean '%s'?", similar)
+			}
+			if !allowUnknownKeys {
+				return nil, nil, l.errorf(key, "unknown Gamma config key%s", hint)
+			}
+			warnings = append(warnings, l.errorf(key, "unknown Gamma config key is passed to the server as is%s", hint))
+			continue
+		}
+		if !isGammaConfigValueValid(kind, values[key]) {
+			return nil, nil, l.errorf(key, "value should be %s, current value: %s", gammaConfigKindNames[kind], jsonValueString(values[key]))
+		}
+	}
+	return &GammaConfigOverride{values: values}, warnings, nil
+}
+
+func (o *GammaConfigOverride) Marshal() ([]byte, error) {
+	return json.Marshal(o.values)
+}
+
+func (o *GammaConfigOverride) Get(key string) (interface{}, bool) {
+	value, found := o.values[key]
+	return value, found
+}
+
+// key = value lines of the merged config sorted by key, covering every known key and every overridden one
+// each value is marked as overridden or default, keys without a default known to gamma-cli keep the one of the server image
+func (o *GammaConfigOverride) Describe(defaults map[string]interface{}) []string {
+	merged := make(map[string]interface{})
+	for key := range gammaConfigKeys {
+		merged[key] = nil
+	}
+	for key, value := range defaults {
+		merged[key] = value
+	}
+	for key, value := range o.values {
+		merged[key] = value
+	}
+
+	var res []string
+	for _, key := range sortedKeys(merged) {
+		_, overridden := o.values[key]
+		switch {
+		case overridden:
+			res = append(res, fmt.Sprintf("%s = %s (override)", key, jsonValueString(merged[key])))
+		case merged[key] != nil:
+			res = append(res, fmt.Sprintf("%s = %s (default)", key, jsonValueString(merged[key])))
+		default:
+			res = append(res, fmt.Sprintf("%s = (default of the image)", key))
+		}
+	}
+	return res
+}
+
+func GammaConfigKeys() []string {
+	var res []string
+	for key := range gammaConfigKeys {
+		res = append(res, key)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func isGammaConfigValueValid(kind gammaConfigKind, value interface{}) bool {
+	switch kind {
+	case GAMMA_CONFIG_UINT:
+		number, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		n, err := number.Int64()
+		return err == nil && n >= 0
+	case GAMMA_CONFIG_BOOL:
+		_, ok := value.(bool)
+		return ok
+	case GAMMA_CONFIG_STRING:
+		_, ok := value.(string)
+		return ok
+	case GAMMA_CONFIG_DURATION:
+		s, ok := value.(string)
+		if !ok {
+			return false
+		}
+		_, err := time.ParseDuration(s)
+		return err == nil
+	case GAMMA_CONFIG_HEX:
+		s, ok := value.(string)
+		if !ok {
+			return false
+		}
+		_, err := simpleDecodeHex(s)
+		return err == nil
+	case GAMMA_CONFIG_HEX_ARRAY:
+		values, ok := value.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, v := range values {
+			if !isGammaConfigValueValid(GAMMA_CONFIG_HEX, v) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// keys differing by a few characters or by separators (eg. virtual_chain_id or VirtualChainId)
+func similarGammaConfigKey(key string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", "_", "", ".", "").Replace(key))
+	for _, known := range GammaConfigKeys() {
+		if strings.Replace(known, "-", "", -1) == normalized || editDistance(known, strings.ToLower(key)) <= 2 {
+			return known
+		}
+	}
+	return ""
+}
+
+func jsonValueString(value interface{}) string {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(bytes)
+}
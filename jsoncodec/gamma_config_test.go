@@ -0,0 +1,88 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package jsoncodec
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestUnmarshalGammaConfigOverride(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expectedErr   string
+		expectedWarns int
+	}{
+		{"Empty", `{}`, "", 0},
+		{"KnownKeys", `{"virtual-chain-id": 43, "transaction-pool-time-between-empty-blocks": "1s", "lean-helix-show-debug": true, "genesis-validator-addresses": ["a328846cd5b4979d68a8c58a9bdfeee657b34de7"]}`, "", 0},
+		{"UnknownKey", `{"some-future-key": 1}`, "override.json:1:2: some-future-key: unknown Gamma config key", 0},
+		{"InvalidJson", `{"virtual-chain-id": 43`, "override.json", 0},
+		{"NotAnObject", `[43]`, "override.json:1:", 0},
+		{"Null", `null`, "override config should be a json object", 0},
+		{"TrailingData", `{} {}`, "unexpected data after the json object", 0},
+		{"NegativeNumber", `{"virtual-chain-id": -1}`, "override.json:1:2: virtual-chain-id: value should be a non negative integer", 0},
+		{"FractionNumber", `{"virtual-chain-id": 4.5}`, "value should be a non negative integer", 0},
+		{"NumberAsString", `{"virtual-chain-id": "43"}`, "value should be a non negative integer", 0},
+		{"InvalidDuration", `{"block-sync-no-commit-interval": "5 seconds"}`, "value should be a duration string", 0},
+		{"InvalidBool", `{"profiling": "yes"}`, "value should be true or false", 0},
+		{"InvalidHex", `{"node-address": "xyz"}`, "value should be a hex string", 0},
+		{"InvalidHexArray", `{"genesis-validator-addresses": "a328"}`, "value should be an array of hex strings", 0},
+		{"Typo", `{"virtual-chian-id": 43}`, "did you mean 'virtual-chain-id'?", 0},
+		{"OtherSeparators", `{"virtual_chain_id": 43}`, "did you mean 'virtual-chain-id'?", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			override, warnings, err := UnmarshalGammaConfigOverride("override.json", []byte(tt.input), false)
+			if tt.expectedErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, override)
+			require.Len(t, warnings, tt.expectedWarns)
+		})
+	}
+}
+
+func TestGammaConfigOverride_MarshalKeepsNumbers(t *testing.T) {
+	override, _, err := UnmarshalGammaConfigOverride("override.json", []byte(`{
+  "virtual-chain-id": 12345678901,
+  "profiling": true
+}`), false)
+	require.NoError(t, err)
+
+	res, err := override.Marshal()
+	require.NoError(t, err)
+	require.Equal(t, `{"profiling":true,"virtual-chain-id":12345678901}`, string(res))
+}
+
+func TestGammaConfigOverride_Describe(t *testing.T) {
+	override, _, err := UnmarshalGammaConfigOverride("override.json", []byte(`{"transaction-pool-time-between-empty-blocks": "1s"}`), false)
+	require.NoError(t, err)
+
+	lines := override.Describe(map[string]interface{}{"virtual-chain-id": 42})
+	require.Len(t, lines, len(GammaConfigKeys()), "every known key should be described")
+	require.Contains(t, lines, `transaction-pool-time-between-empty-blocks = "1s" (override)`)
+	require.Contains(t, lines, `virtual-chain-id = 42 (default)`)
+	require.Contains(t, lines, `profiling = (default of the image)`)
+}
+
+func TestUnmarshalGammaConfigOverride_AllowUnknownKeys(t *testing.T) {
+	override, warnings, err := UnmarshalGammaConfigOverride("override.json", []byte(`{"virtual_chain_id": 43}`), true)
+	require.NoError(t, err, "unknown keys should be allowed for newer servers")
+	require.Len(t, warnings, 1)
+	require.Contains(t, warnings[0].Error(), "did you mean 'virtual-chain-id'?", "warning should name the similar known key")
+
+	value, found := override.Get("virtual_chain_id")
+	require.True(t, found, "unknown key should be passed to the server")
+	require.Equal(t, "43", jsonValueString(value))
+
+	_, _, err = UnmarshalGammaConfigOverride("override.json", []byte(`{"profiling": "yes"}`), true)
+	require.Error(t, err, "values of known keys should still be validated")
+}
@@ -0,0 +1,275 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package jsoncodec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const INPUT_FORMAT_JSON = "json"
+const INPUT_FORMAT_YAML = "yaml"
+const INPUT_FORMAT_TOML = "toml"
+
+var yamlErrorLinePattern = regexp.MustCompile(`^yaml: line ([0-9]+): (.*)$`)
+var tomlErrorLinePattern = regexp.MustCompile(`^toml: line [0-9]+( \(last key "[^"]*"\))?: `)
+
+// files ending with .yaml or .yml are yaml, .toml are toml and all others are json
+func InputFormat(filename string) string {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".yaml", ".yml":
+		return INPUT_FORMAT_YAML
+	case ".toml":
+		return INPUT_FORMAT_TOML
+	default:
+		return INPUT_FORMAT_JSON
+	}
+}
+
+// yaml and toml are converted to json first so all formats share the field names and decoding rules of the json structures
+// parse and decode errors are of the form filename:line:column: message in every format
+func unmarshalInput(filename string, input []byte, v interface{}) error {
+	jsonBytes, err := inputToJson(filename, input)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(jsonBytes, v)
+	if err == nil {
+		return nil
+	}
+	if InputFormat(filename) != INPUT_FORMAT_JSON {
+		return convertedInputError(filename, input, jsonBytes, err)
+	}
+	return jsonInputError(filename, input, err)
+}
+
+// like unmarshalInput, with coerce applied to the generic values of yaml and toml input first (eg. to native numbers of argument values)
+func unmarshalCoercedInput(filename string, input []byte, v interface{}, coerce func(raw interface{})) error {
+	if InputFormat(filename) == INPUT_FORMAT_JSON {
+		return unmarshalInput(filename, input, v)
+	}
+	jsonBytes, err := inputToJson(filename, input)
+	if err != nil {
+		return err
+	}
+
+	var raw interface{}
+	dec := json.NewDecoder(bytes.NewReader(jsonBytes))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return convertedInputError(filename, input, jsonBytes, err)
+	}
+	coerce(raw)
+	coerced, err := json.Marshal(raw)
+	if err != nil {
+		return errors.Errorf("%s: %s", filename, err.Error())
+	}
+	if err := json.Unmarshal(coerced, v); err != nil {
+		return convertedInputError(filename, input, coerced, err)
+	}
+	return nil
+}
+
+// maps the json paths of the input (eg. Arguments[2].Value) to their offsets in it, whatever its format
+func inputPathOffsets(filename string, input []byte) map[string]int64 {
+	switch InputFormat(filename) {
+	case INPUT_FORMAT_YAML:
+		return yamlPathOffsets(input)
+	case INPUT_FORMAT_TOML:
+		return tomlPathOffsets(input)
+	default:
+		return jsonPathOffsets(input)
+	}
+}
+
+func inputToJson(filename string, input []byte) ([]byte, error) {
+	switch InputFormat(filename) {
+	case INPUT_FORMAT_YAML:
+		converted, err := yamlToJson(input)
+		if err != nil {
+			return nil, yamlInputError(filename, input, err)
+		}
+		return converted, nil
+	case INPUT_FORMAT_TOML:
+		converted, err := tomlToJson(input)
+		if err != nil {
+			return nil, tomlInputError(filename, input, err)
+		}
+		return converted, nil
+	default:
+		return input, nil
+	}
+}
+
+func tomlToJson(input []byte) ([]byte, error) {
+	var value map[string]interface{}
+	if _, err := toml.Decode(string(input), &value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// toml lists the keys of a document in the order they appear but not where, so each key is looked up in the input
+// after the previous one, inline tables in arrays are told apart by the braces between their keys
+func tomlPathOffsets(input []byte) map[string]int64 {
+	res := make(map[string]int64)
+	var value map[string]interface{}
+	md, err := toml.Decode(string(input), &value)
+	if err != nil {
+		return res
+	}
+
+	indexes := make(map[string]int)       // path of an array of tables -> index of its current table
+	inlineOffsets := make(map[string]int) // path of an inline array of tables -> offset its braces were counted to
+	cursor := 0
+	for _, key := range md.Keys() {
+		offset := indexTomlKey(input, cursor, key[len(key)-1])
+		if offset < 0 {
+			continue
+		}
+		cursor = offset + 1
+
+		path := ""
+		for i, part := range key {
+			path = joinJsonPath(path, part)
+			if _, found := res[path]; !found {
+				res[path] = int64(offset)
+			}
+			isLast := i == len(key)-1
+			switch md.Type(key[:i+1]...) {
+			case "ArrayHash":
+				if isLast {
+					indexes[path]++
+				}
+			case "Array":
+				if isLast {
+					inlineOffsets[path] = offset
+					indexes[path] = 0
+					continue
+				}
+				indexes[path] += countTomlInlineTables(input[inlineOffsets[path]:offset])
+				inlineOffsets[path] = offset
+			default:
+				continue
+			}
+			path = fmt.Sprintf("%s[%d]", path, indexes[path]-1)
+			if _, found := res[path]; !found {
+				res[path] = int64(offset)
+			}
+		}
+	}
+	return res
+}
+
+// the offset of a bare or quoted key (or table name part) in the input from start, -1 when not found
+func indexTomlKey(input []byte, start int, key string) int {
+	for _, token := range []string{key, `"` + key + `"`, "'" + key + "'"} {
+		for i := start; i <= len(input)-len(token); {
+			found := bytes.Index(input[i:], []byte(token))
+			if found < 0 {
+				break
+			}
+			at := i + found
+			end := at + len(token)
+			if (at == 0 || strings.IndexByte(" \t\n{[.,", input[at-1]) >= 0) && (end == len(input) || strings.IndexByte(" \t=.]", input[end]) >= 0) {
+				return at
+			}
+			i = at + 1
+		}
+	}
+	return -1
+}
+
+func countTomlInlineTables(input []byte) int {
+	res := 0
+	var quote byte
+	for _, c := range input {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '{':
+			res++
+		}
+	}
+	return res
+}
+func jsonInputError(filename string, input []byte, err error) error {
+	var offset int64
+	switch err := err.(type) {
+	case *json.SyntaxError:
+		offset = err.Offset
+	case *json.UnmarshalTypeError:
+		offset = err.Offset
+	default:
+		return errors.Errorf("%s: %s", filename, err.Error())
+	}
+
+	line, column := lineAndColumn(input, offset)
+	return errors.Errorf("%s:%d:%d: %s", filename, line, column, err.Error())
+}
+
+// decode errors of yaml and toml input point into the json converted from it, they are mapped back through the path of the value
+func convertedInputError(filename string, input []byte, converted []byte, err error) error {
+	typeErr, ok := err.(*json.UnmarshalTypeError)
+	if !ok {
+		return errors.Errorf("%s: %s", filename, err.Error())
+	}
+	path := jsonPathAt(jsonPathOffsets(converted), typeErr.Offset)
+	return newInputLocator(filename, input).errorAt(path, err.Error())
+}
+
+// yaml reports only the line of a parse error, the column is where the content of that line starts
+func yamlInputError(filename string, input []byte, err error) error {
+	if m := yamlErrorLinePattern.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return errors.Errorf("%s:%d:%d: %s", filename, line, lineIndentColumn(input, line), m[2])
+	}
+	return errors.Errorf("%s: %s", filename, strings.TrimPrefix(err.Error(), "yaml: "))
+}
+
+func tomlInputError(filename string, input []byte, err error) error {
+	if parseErr, ok := err.(toml.ParseError); ok {
+		line, column := lineAndColumn(input, int64(parseErr.Position.Start+1))
+		message := tomlErrorLinePattern.ReplaceAllString(parseErr.Error(), "")
+		return errors.Errorf("%s:%d:%d: %s", filename, line, column, message)
+	}
+	return errors.Errorf("%s: %s", filename, err.Error())
+}
+
+// the offset is the number of bytes read when the error was detected, so the offending byte is the one before it
+func lineAndColumn(input []byte, offset int64) (int, int) {
+	if offset > 0 {
+		offset--
+	}
+	if offset > int64(len(input)) {
+		offset = int64(len(input))
+	}
+	before := input[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
+
+func lineIndentColumn(input []byte, line int) int {
+	lines := bytes.Split(input, []byte("\n"))
+	if line < 1 || line > len(lines) {
+		return 1
+	}
+	return len(lines[line-1]) - len(bytes.TrimLeft(lines[line-1], " \t")) + 1
+}
@@ -0,0 +1,123 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package jsoncodec
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestUnmarshalSendTx_Formats(t *testing.T) {
+	tests := []struct {
+		filename string
+		input    string
+	}{
+		{"transfer.json", `{"ContractName": "MyToken", "MethodName": "transfer", "Arguments": [{"Type": "uint64", "Value": "10"}]}`},
+		{"transfer.yaml", `
+# fixture: transfers 10 tokens
+ContractName: MyToken
+MethodName: transfer
+Arguments:
+  - Type: uint64
+    Value: "10" # amount in whole tokens
+`},
+		{"transfer.toml", `
+# fixture: transfers 10 tokens
+ContractName = "MyToken"
+MethodName = "transfer"
+
+[[Arguments]]
+Type = "uint64"
+Value = "10" # amount in whole tokens
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			sendTx, err := UnmarshalSendTx(tt.filename, []byte(tt.input))
+			require.NoError(t, err, "input should be parsed")
+			require.Equal(t, "MyToken", sendTx.ContractName)
+			require.Equal(t, "transfer", sendTx.MethodName)
+			require.Len(t, sendTx.Arguments, 1)
+			require.Equal(t, "uint64", sendTx.Arguments[0].Type)
+			require.Equal(t, "10", sendTx.Arguments[0].Value)
+		})
+	}
+}
+
+func TestUnmarshalSendTx_NativeScalars(t *testing.T) {
+	tests := []struct {
+		filename string
+		input    string
+		expected string
+	}{
+		{"transfer.yaml", "ContractName: MyToken\nMethodName: transfer\nArguments:\n  - Type: uint64\n    Value: 18446744073709551615\n  - Type: bool\n    Value: true\n  - Type: uint32Array\n    Value: [1, 2]\n", "18446744073709551615"},
+		{"transfer.toml", "ContractName = \"MyToken\"\nMethodName = \"transfer\"\n[[Arguments]]\nType = \"uint64\"\nValue = 9223372036854775807\n[[Arguments]]\nType = \"bool\"\nValue = true\n[[Arguments]]\nType = \"uint32Array\"\nValue = [1, 2]\n", "9223372036854775807"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			sendTx, err := UnmarshalSendTx(tt.filename, []byte(tt.input))
+			require.NoError(t, err, "input should be parsed")
+			require.Equal(t, tt.expected, sendTx.Arguments[0].Value, "numbers should keep all their digits")
+			require.Equal(t, "1", sendTx.Arguments[1].Value, "bools should be 1 or 0")
+			require.Equal(t, []interface{}{"1", "2"}, sendTx.Arguments[2].Value, "array elements should be coerced")
+
+			_, err = UnmarshalArgs(sendTx.Arguments, nil, nil, nil)
+			require.NoError(t, err, "coerced values should be valid arguments")
+		})
+	}
+}
+
+func TestUnmarshalSendTx_FormatErrors(t *testing.T) {
+	tests := []struct {
+		filename string
+		input    string
+		expected string
+	}{
+		{"transfer.json", "{\n  \"ContractName\": \"MyToken\",\n  \"MethodName\" \"transfer\"\n}", "transfer.json:3:16: "},
+		{"transfer.json", "{\n  \"ContractName\": 5\n}", "transfer.json:2:19: "},
+		{"transfer.yaml", "ContractName: MyToken\nMethodName: [transfer\n", "transfer.yaml:2:1: "},
+		{"transfer.toml", "ContractName = \"MyToken\"\nMethodName = transfer\n", "transfer.toml:2:14: "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			_, err := UnmarshalSendTx(tt.filename, []byte(tt.input))
+			require.Error(t, err, "invalid input should fail")
+			require.Contains(t, err.Error(), tt.expected, "error should point to the line and column")
+		})
+	}
+}
+
+func TestUnmarshalDeployManifest_DecodeErrors(t *testing.T) {
+	tests := []struct {
+		filename string
+		input    string
+		expected string
+	}{
+		{"contracts.json", "{\"Contracts\": [\n  {\"Source\": \"token.go\"},\n  {\"Source\": [\"exchange\"]}\n]}", "contracts.json:3:14: "},
+		{"contracts.yaml", "Contracts:\n  - Source: token.go\n  - Name: Exchange\n    Source: [exchange]\n", "contracts.yaml:4:5: "},
+		{"contracts.toml", "[[Contracts]]\nSource = \"token.go\"\n\n[[Contracts]]\nName = \"Exchange\"\nSource = 5\n", "contracts.toml:6:1: "},
+		{"contracts.toml", "[[Contracts]]\nSource = \"token.go\"\n  [[Contracts.Init]]\n  MethodName = \"mint\"\n  Arguments = [{Type = \"uint64\", Value = \"1\"}, {Type = 5}]\n", "contracts.toml:5:49: "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			_, err := UnmarshalDeployManifest(tt.filename, []byte(tt.input))
+			require.Error(t, err, "mistyped values should fail")
+			require.Contains(t, err.Error(), tt.expected, "error should point to the line and column of the value in the original file")
+		})
+	}
+}
+
+func TestMarshalKeys_Toml(t *testing.T) {
+	keys := map[string]*Key{"user1": {PrivateKey: "0x01", PublicKey: "0x02", Address: "0x03"}}
+
+	bytes, err := MarshalKeys("keys.toml", keys)
+	require.NoError(t, err, "keys should be encoded")
+
+	decoded, err := UnmarshalKeys("keys.toml", bytes)
+	require.NoError(t, err, "encoded keys should be decoded")
+	require.Equal(t, keys, decoded, "keys should round trip through toml")
+}
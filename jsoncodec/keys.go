@@ -6,7 +6,11 @@
 
 package jsoncodec
 
-import "encoding/json"
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/BurntSushi/toml"
+)
 
 type Key struct {
 	PrivateKey string // hex string starting with 0x
@@ -20,12 +24,18 @@ type RawKey struct {
 	Address    []byte
 }
 
-func UnmarshalKeys(bytes []byte) (map[string]*Key, error) {
+func UnmarshalKeys(filename string, bytes []byte) (map[string]*Key, error) {
 	keys := make(map[string]*Key)
-	err := json.Unmarshal(bytes, &keys)
+	err := unmarshalInput(filename, bytes, &keys)
 	return keys, err
 }
 
-func MarshalKeys(keys map[string]*Key) ([]byte, error) {
+// toml key files are written as toml, all others as json (which is also valid yaml)
+func MarshalKeys(filename string, keys map[string]*Key) ([]byte, error) {
+	if InputFormat(filename) == INPUT_FORMAT_TOML {
+		var buf bytes.Buffer
+		err := toml.NewEncoder(&buf).Encode(keys)
+		return buf.Bytes(), err
+	}
 	return json.MarshalIndent(keys, "", "  ")
 }
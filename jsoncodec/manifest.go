@@ -0,0 +1,52 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package jsoncodec
+
+import "strings"
+
+type DeployManifest struct {
+	Contracts []*ManifestContract
+}
+
+type ManifestContract struct {
+	Name      string
+	Source    string    // code file or dir, relative to the manifest
+	Signer    string    // id from keys json, defaults to -signer
+	Processor string    // native or javascript, detected from the source file extension if omitted
+	DependsOn []string  // names of contracts that must be deployed first
+	Init      []*SendTx // transactions sent after deploy, ContractName defaults to this contract and is resolved through the contract aliases otherwise
+}
+
+// manifests ending with .yaml or .yml are parsed as yaml, .toml as toml and all others as json
+// native numbers and bools of init call arguments are coerced like in send-tx input
+func UnmarshalDeployManifest(filename string, bytes []byte) (*DeployManifest, error) {
+	var manifest *DeployManifest
+	err := unmarshalCoercedInput(filename, bytes, &manifest, coerceManifestArgValues)
+	return manifest, err
+}
+
+func coerceManifestArgValues(raw interface{}) {
+	fields, _ := raw.(map[string]interface{})
+	for key, value := range fields {
+		if !strings.EqualFold(key, "Contracts") {
+			continue
+		}
+		contracts, _ := value.([]interface{})
+		for _, rawContract := range contracts {
+			contract, _ := rawContract.(map[string]interface{})
+			for contractKey, contractValue := range contract {
+				if !strings.EqualFold(contractKey, "Init") {
+					continue
+				}
+				calls, _ := contractValue.([]interface{})
+				for _, call := range calls {
+					coerceArgValues(call)
+				}
+			}
+		}
+	}
+}
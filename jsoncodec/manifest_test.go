@@ -0,0 +1,75 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package jsoncodec
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestUnmarshalDeployManifest_Yaml(t *testing.T) {
+	input := `
+Contracts:
+  - Name: Token
+    Source: token.go
+    Signer: user2
+    Init:
+      - MethodName: mint
+        Arguments:
+          - Type: uint64
+            Value: "1000"
+  - Source: exchange
+    DependsOn: [Token]
+`
+	manifest, err := UnmarshalDeployManifest("contracts.yaml", []byte(input))
+	require.NoError(t, err, "unmarshal yaml manifest should not fail")
+	require.Equal(t, &DeployManifest{
+		Contracts: []*ManifestContract{
+			{Name: "Token", Source: "token.go", Signer: "user2", Init: []*SendTx{{MethodName: "mint", Arguments: []*Arg{{"uint64", "1000"}}}}},
+			{Source: "exchange", DependsOn: []string{"Token"}},
+		},
+	}, manifest)
+}
+
+func TestUnmarshalDeployManifest_NativeInitArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		input    string
+	}{
+		{"Yaml", "contracts.yaml", `
+Contracts:
+  - Source: token.go
+    Init:
+      - MethodName: mint
+        Arguments:
+          - Type: uint64
+            Value: 10
+          - Type: bool
+            Value: true
+`},
+		{"Toml", "contracts.toml", `
+[[Contracts]]
+Source = "token.go"
+  [[Contracts.Init]]
+  MethodName = "mint"
+    [[Contracts.Init.Arguments]]
+    Type = "uint64"
+    Value = 10
+    [[Contracts.Init.Arguments]]
+    Type = "bool"
+    Value = true
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest, err := UnmarshalDeployManifest(tt.filename, []byte(tt.input))
+			require.NoError(t, err, "native init argument values should be accepted")
+			require.Equal(t, []*Arg{{"uint64", "10"}, {"bool", "1"}}, manifest.Contracts[0].Init[0].Arguments)
+		})
+	}
+}
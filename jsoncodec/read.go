@@ -19,18 +19,23 @@ type Read struct {
 	Arguments    []*Arg
 }
 
-func UnmarshalRead(bytes []byte) (*Read, error) {
+func UnmarshalRead(filename string, bytes []byte) (*Read, error) {
 	var read *Read
-	err := json.Unmarshal(bytes, &read)
-	return read, err
+	if err := unmarshalCallInput(filename, bytes, &read); err != nil {
+		return nil, err
+	}
+	if err := expandCallInput(newInputLocator(filename, bytes), &read.ContractName, &read.MethodName, read.Arguments); err != nil {
+		return nil, err
+	}
+	return read, nil
 }
 
-func MarshalReadResponse(r *codec.RunQueryResponse) ([]byte, error) {
-	outputArgs, err := MarshalArgs(r.OutputArguments)
+func MarshalReadResponse(r *codec.RunQueryResponse, opts *ArgsOptions) ([]byte, error) {
+	outputArgs, err := MarshalArgs(r.OutputArguments, opts)
 	if err != nil {
 		return nil, errors.Errorf("Read response marshaling output arguments failed with %s \n", err.Error())
 	}
-	outputEvents, err := MarshalEvents(r.OutputEvents)
+	outputEvents, err := MarshalEvents(r.OutputEvents, opts)
 	if err != nil {
 		return nil, errors.Errorf("Read response marshaling output events failed with %s \n", err.Error())
 	}
@@ -19,18 +19,27 @@ type SendTx struct {
 	Arguments    []*Arg
 }
 
-func UnmarshalSendTx(bytes []byte) (*SendTx, error) {
+func UnmarshalSendTx(filename string, bytes []byte) (*SendTx, error) {
 	var sendTx *SendTx
-	err := json.Unmarshal(bytes, &sendTx)
-	return sendTx, err
+	if err := unmarshalCallInput(filename, bytes, &sendTx); err != nil {
+		return nil, err
+	}
+	if err := expandCallInput(newInputLocator(filename, bytes), &sendTx.ContractName, &sendTx.MethodName, sendTx.Arguments); err != nil {
+		return nil, err
+	}
+	return sendTx, nil
+}
+
+func MarshalSendTx(sendTx *SendTx) ([]byte, error) {
+	return json.MarshalIndent(sendTx, "", "  ")
 }
 
-func MarshalSendTxResponse(r *codec.SendTransactionResponse, txId string) ([]byte, error) {
-	outputArgs, err := MarshalArgs(r.OutputArguments)
+func MarshalSendTxResponse(r *codec.SendTransactionResponse, txId string, opts *ArgsOptions) ([]byte, error) {
+	outputArgs, err := MarshalArgs(r.OutputArguments, opts)
 	if err != nil {
 		return nil, errors.Errorf("Send Tx response marshaling output arguments failed with %s \n", err.Error())
 	}
-	outputEvents, err := MarshalEvents(r.OutputEvents)
+	outputEvents, err := MarshalEvents(r.OutputEvents, opts)
 	if err != nil {
 		return nil, errors.Errorf("Send Tx response marshaling output events failed with %s \n", err.Error())
 	}
@@ -0,0 +1,108 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package jsoncodec
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"regexp"
+	"strings"
+)
+
+// returns the value of a template variable and whether it is defined
+type TemplateLookup func(name string) (string, bool, error)
+
+// matches ${NAME} placeholders, $${ is an escaped literal ${ and any other $ is kept as is
+var templatePattern = regexp.MustCompile(`\$\$\{|\$\{([^}]*)\}`)
+
+// no lookup means input files are used as is (eg. with -no-template)
+var templateLookup TemplateLookup
+
+func SetTemplateLookup(lookup TemplateLookup) {
+	templateLookup = lookup
+}
+
+// replaces the ${NAME} placeholders of the string fields of a parsed send-tx or run-query input
+// expanding after parsing keeps positions in errors pointing into the file and needs no escaping for its format
+func expandCallInput(l *inputLocator, contractName *string, methodName *string, args []*Arg) error {
+	if templateLookup == nil {
+		return nil
+	}
+
+	var undefined []string
+	expand := func(path string, value string) (string, error) {
+		res, missing, err := expandTemplate(value)
+		if err != nil {
+			return "", l.errorf(path, "%s", err.Error())
+		}
+		undefined = append(undefined, missing...)
+		return res, nil
+	}
+
+	var err error
+	if *contractName, err = expand("ContractName", *contractName); err != nil {
+		return err
+	}
+	if *methodName, err = expand("MethodName", *methodName); err != nil {
+		return err
+	}
+	for i, arg := range args {
+		if arg.Type, err = expand(fmt.Sprintf("Arguments[%d].Type", i), arg.Type); err != nil {
+			return err
+		}
+		if arg.Value, err = expandTemplateValue(fmt.Sprintf("Arguments[%d].Value", i), arg.Value, expand); err != nil {
+			return err
+		}
+	}
+
+	if len(undefined) > 0 {
+		return errors.Errorf("%s: undefined template variables: %s\nDefine them as environment variables, in a -vars json file or with -set NAME=VALUE", l.filename, strings.Join(undefined, " "))
+	}
+	return nil
+}
+
+// strings and the strings of arrays (array argument types), other values are left as is
+func expandTemplateValue(path string, value interface{}, expand func(string, string) (string, error)) (interface{}, error) {
+	switch value := value.(type) {
+	case string:
+		return expand(path, value)
+	case []interface{}:
+		for i, v := range value {
+			expanded, err := expandTemplateValue(fmt.Sprintf("%s[%d]", path, i), v, expand)
+			if err != nil {
+				return nil, err
+			}
+			value[i] = expanded
+		}
+		return value, nil
+	default:
+		return value, nil
+	}
+}
+
+// returns the expanded string and the names of the undefined variables, which are left as is
+func expandTemplate(value string) (string, []string, error) {
+	var undefined []string
+	var lookupErr error
+	res := templatePattern.ReplaceAllStringFunc(value, func(match string) string {
+		if match == "$${" {
+			return "${"
+		}
+
+		name := strings.TrimSpace(match[2 : len(match)-1])
+		value, found, err := templateLookup(name)
+		if err != nil && lookupErr == nil {
+			lookupErr = errors.Wrapf(err, "template variable '%s'", name)
+		}
+		if !found {
+			undefined = append(undefined, name)
+			return match
+		}
+		return value
+	})
+	return res, undefined, lookupErr
+}
@@ -0,0 +1,84 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package jsoncodec
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestUnmarshalSendTx_Template(t *testing.T) {
+	vars := map[string]string{
+		"TOKEN":              "MyToken",
+		"keys.user2.Address": "0x5B63Ca66637316A0D7f84Ebf60E50963c10059aD",
+		"QUOTED":             `say "hi"`,
+	}
+	SetTemplateLookup(func(name string) (string, bool, error) {
+		value, found := vars[name]
+		return value, found, nil
+	})
+	defer SetTemplateLookup(nil)
+
+	input := `{
+  "ContractName": "${TOKEN}",
+  "MethodName": "transfer",
+  "Arguments": [
+    {"Type": "bytes20", "Value": "${keys.user2.Address}"},
+    {"Type": "string", "Value": "${QUOTED} costs $5 in $${currency}"}
+  ]
+}`
+	sendTx, err := UnmarshalSendTx("transfer.json", []byte(input))
+	require.NoError(t, err, "template should be expanded")
+	require.Equal(t, "MyToken", sendTx.ContractName, "contract name should be substituted")
+	require.Equal(t, "0x5B63Ca66637316A0D7f84Ebf60E50963c10059aD", sendTx.Arguments[0].Value, "built-in should be substituted")
+	require.Equal(t, `say "hi" costs $5 in ${currency}`, sendTx.Arguments[1].Value, "values should be inserted as is and $${ should be a literal ${")
+
+	_, err = UnmarshalRead("balance.json", []byte(`{"ContractName": "${MISSING}", "MethodName": "${ALSO_MISSING}"}`))
+	require.Error(t, err, "undefined variables should fail")
+	require.Contains(t, err.Error(), "MISSING ALSO_MISSING", "error should list the undefined variables")
+}
+
+func TestUnmarshalSendTx_TemplateKeepsPositions(t *testing.T) {
+	SetTemplateLookup(func(name string) (string, bool, error) {
+		return "a much longer value than the placeholder", true, nil
+	})
+	defer SetTemplateLookup(nil)
+
+	input := "{\n  \"ContractName\": \"${TOKEN}\",\n  \"MethodName\": \"transfer\",\n  \"Arguments\": [{\"Type\": \"uint64\"}]\n}"
+	_, err := UnmarshalSendTx("transfer.json", []byte(input))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "transfer.json:4:17: Arguments[0]: Value is missing", "positions should point into the file on disk")
+}
+
+func TestUnmarshalSendTx_TemplateFormats(t *testing.T) {
+	SetTemplateLookup(func(name string) (string, bool, error) {
+		return `a"b`, true, nil
+	})
+	defer SetTemplateLookup(nil)
+
+	tests := []struct {
+		filename string
+		input    string
+	}{
+		{"transfer.json", `{"ContractName": "Token", "MethodName": "transfer", "Arguments": [{"Type": "string", "Value": "${X}"}]}`},
+		{"transfer.yaml", "ContractName: Token\nMethodName: transfer\nArguments:\n  - Type: string\n    Value: ${X}\n"},
+		{"transfer.toml", "ContractName = \"Token\"\nMethodName = \"transfer\"\n[[Arguments]]\nType = \"string\"\nValue = \"${X}\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			sendTx, err := UnmarshalSendTx(tt.filename, []byte(tt.input))
+			require.NoError(t, err)
+			require.Equal(t, `a"b`, sendTx.Arguments[0].Value, "values should not be escaped for any format")
+		})
+	}
+}
+
+func TestUnmarshalSendTx_NoTemplateLookup(t *testing.T) {
+	sendTx, err := UnmarshalSendTx("transfer.json", []byte(`{"ContractName": "${TOKEN}", "MethodName": "transfer"}`))
+	require.NoError(t, err, "input should be parsed as is")
+	require.Equal(t, "${TOKEN}", sendTx.ContractName, "placeholders should be left as is without a lookup")
+}
@@ -15,12 +15,12 @@ import (
 	"strconv"
 )
 
-func MarshalTxProofResponse(r *codec.GetTransactionReceiptProofResponse) ([]byte, error) {
-	outputArgs, err := MarshalArgs(r.OutputArguments)
+func MarshalTxProofResponse(r *codec.GetTransactionReceiptProofResponse, opts *ArgsOptions) ([]byte, error) {
+	outputArgs, err := MarshalArgs(r.OutputArguments, opts)
 	if err != nil {
 		return nil, errors.Errorf("Tx proof response marshaling output arguments failed with %s \n", err.Error())
 	}
-	outputEvents, err := MarshalEvents(r.OutputEvents)
+	outputEvents, err := MarshalEvents(r.OutputEvents, opts)
 	if err != nil {
 		return nil, errors.Errorf("Tx proof response marshaling output events failed with %s \n", err.Error())
 	}
@@ -13,12 +13,12 @@ import (
 	"strconv"
 )
 
-func MarshalTxStatusResponse(r *codec.GetTransactionStatusResponse) ([]byte, error) {
-	outputArgs, err := MarshalArgs(r.OutputArguments)
+func MarshalTxStatusResponse(r *codec.GetTransactionStatusResponse, opts *ArgsOptions) ([]byte, error) {
+	outputArgs, err := MarshalArgs(r.OutputArguments, opts)
 	if err != nil {
 		return nil, errors.Errorf("Tx status response marshaling output arguments failed with %s \n", err.Error())
 	}
-	outputEvents, err := MarshalEvents(r.OutputEvents)
+	outputEvents, err := MarshalEvents(r.OutputEvents, opts)
 	if err != nil {
 		return nil, errors.Errorf("Tx status response marshaling output events failed with %s \n", err.Error())
 	}
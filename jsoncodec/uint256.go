@@ -0,0 +1,186 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package jsoncodec
+
+import (
+	"encoding/hex"
+	"github.com/pkg/errors"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const UINT256_OUTPUT_HEX = "hex"
+const UINT256_OUTPUT_DECIMAL = "decimal"
+const MAX_UINT256_DECIMAL_DIGITS = 78
+
+var maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// unit suffixes accepted in uint256 input, mapped to their number of decimals (eg. "10 ether")
+var uint256UnitPattern = regexp.MustCompile(`^[a-zA-Z]+$`)
+
+var uint256Units = map[string]int{
+	"wei":   0,
+	"gwei":  9,
+	"ether": 18,
+}
+
+var uint256DecimalPattern = regexp.MustCompile(`^([0-9]+)(?:\.([0-9]+))?(?:[eE]([0-9]+))?(?:\s*([a-zA-Z]+))?$`)
+
+func (o *ArgsOptions) validateUint256() error {
+	for unit, decimals := range o.uint256Units() {
+		if !uint256UnitPattern.MatchString(unit) || decimals < 0 || decimals > MAX_UINT256_DECIMAL_DIGITS {
+			return errors.Errorf("uint256 unit '%s' should be letters only with 0 to %d decimals, current decimals: %d", unit, MAX_UINT256_DECIMAL_DIGITS, decimals)
+		}
+	}
+	if _, _, err := o.uint256OutputFormat(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// the built-in units and the ones of the options, which take precedence
+func (o *ArgsOptions) uint256Units() map[string]int {
+	res := make(map[string]int)
+	for unit, decimals := range uint256Units {
+		res[unit] = decimals
+	}
+	if o != nil {
+		for unit, decimals := range o.Uint256Units {
+			res[strings.ToLower(unit)] = decimals
+		}
+	}
+	return res
+}
+
+// the number of decimals of output values and the suffix that makes them read back as the same value (a unit or an exponent)
+func (o *ArgsOptions) uint256OutputFormat() (int, string, error) {
+	output := ""
+	if o != nil {
+		output = strings.TrimSpace(o.Uint256Output)
+	}
+	switch strings.ToLower(output) {
+	case "", UINT256_OUTPUT_HEX:
+		return -1, "", nil
+	case UINT256_OUTPUT_DECIMAL:
+		return 0, "", nil
+	}
+	if decimals, found := o.uint256Units()[strings.ToLower(output)]; found {
+		return decimals, " " + strings.ToLower(output), nil
+	}
+	decimals, err := strconv.Atoi(output)
+	if err != nil || decimals < 0 || decimals > MAX_UINT256_DECIMAL_DIGITS {
+		return 0, "", errors.Errorf("uint256 output should be hex, decimal, a unit (%s) or a number of decimals, current value: '%s'", knownUint256Units(o), output)
+	}
+	if decimals == 0 {
+		return 0, "", nil
+	}
+	return decimals, "e" + strconv.Itoa(decimals), nil
+}
+
+func isHexUint256(value string) bool {
+	if strings.HasPrefix(value, "0x") {
+		return true
+	}
+	_, err := hex.DecodeString(value)
+	return len(value) == 64 && err == nil
+}
+
+// accepts 32 bytes in hex (64 hexes) or a decimal number with optional fraction, exponent and unit (eg. "1.5e18", "10 ether")
+// for backwards compatibility, exactly 64 hexes without a 0x prefix are hex even when they are all decimal digits,
+// a decimal number of 64 digits needs a unit (eg. "... wei")
+func unmarshalUint256(value string, opts *ArgsOptions) (*big.Int, error) {
+	if isHexUint256(value) {
+		valBytes, err := simpleDecodeHex(value)
+		if err != nil {
+			return nil, errors.Errorf("uint256 value in bytes in a hex format (64 hexes) or a decimal number\nHex decoder returned error: %s\nCurrent value: '%s'", err.Error(), value)
+		}
+		if len(valBytes) != 32 {
+			return nil, errors.Errorf("uint256 value in bytes in a hex format (64 hexes) or a decimal number\n Actual size : %d", len(valBytes))
+		}
+		return new(big.Int).SetBytes(valBytes), nil
+	}
+
+	m := uint256DecimalPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil {
+		return nil, errors.Errorf("uint256 value in bytes in a hex format (64 hexes) or a decimal number (eg. 1000, 1.5e18, 10 ether)\nCurrent value: '%s'", value)
+	}
+	integer, fraction, exponentText, unit := m[1], m[2], m[3], m[4]
+
+	exponent := 0
+	if exponentText != "" {
+		exp, err := strconv.Atoi(exponentText)
+		if err != nil {
+			return nil, errors.Errorf("uint256 value with a valid exponent\nCurrent value: '%s'", value)
+		}
+		exponent = exp
+	}
+	if unit != "" {
+		decimals, found := opts.uint256Units()[strings.ToLower(unit)]
+		if !found {
+			return nil, errors.Errorf("uint256 value with a known unit, '%s' is unknown\nKnown units are: %s\nCurrent value: '%s'", unit, knownUint256Units(opts), value)
+		}
+		exponent += decimals
+	}
+
+	digits := strings.TrimRight(fraction, "0")
+	exponent -= len(digits)
+	digits = integer + digits
+	if exponent < 0 {
+		return nil, errors.Errorf("uint256 value which is a whole number after applying exponent and unit\nCurrent value: '%s'", value)
+	}
+	if exponent > MAX_UINT256_DECIMAL_DIGITS {
+		return nil, errors.Errorf("uint256 value which fits in 256 bits\nCurrent value: '%s'", value)
+	}
+
+	val, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, errors.Errorf("uint256 value as a decimal number\nCurrent value: '%s'", value)
+	}
+	val.Mul(val, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil))
+	if val.Cmp(maxUint256) > 0 {
+		return nil, errors.Errorf("uint256 value which fits in 256 bits\nCurrent value: '%s'", value)
+	}
+	return val, nil
+}
+
+// output with decimals carries its unit or exponent (eg. "1.5 ether", "1.5e18") so it reads back as the same value
+// options are validated before use, an invalid output format falls back to hex
+func marshalUint256(val *big.Int, opts *ArgsOptions) string {
+	decimals, suffix, err := opts.uint256OutputFormat()
+	if err != nil || decimals < 0 {
+		res := [32]byte{}
+		b := val.Bytes()
+		copy(res[32-len(b):], b)
+		return "0x" + hex.EncodeToString(res[:])
+	}
+
+	text := val.String()
+	if decimals == 0 {
+		return text
+	}
+	if len(text) <= decimals {
+		text = strings.Repeat("0", decimals-len(text)+1) + text
+	}
+	integer := text[:len(text)-decimals]
+	fraction := strings.TrimRight(text[len(text)-decimals:], "0")
+	if fraction == "" {
+		return integer + suffix
+	}
+	return integer + "." + fraction + suffix
+}
+
+func knownUint256Units(opts *ArgsOptions) string {
+	var units []string
+	for unit := range opts.uint256Units() {
+		units = append(units, unit)
+	}
+	sort.Strings(units)
+	return strings.Join(units, " ")
+}
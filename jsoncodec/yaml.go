@@ -0,0 +1,98 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package jsoncodec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	yamlnode "gopkg.in/yaml.v3"
+)
+
+// converts yaml to json so yaml input shares the field names and decoding rules of the json structures
+func yamlToJson(bytes []byte) ([]byte, error) {
+	var value interface{}
+	if err := yaml.Unmarshal(bytes, &value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonCompatibleValue(value))
+}
+
+// yaml decodes maps as map[interface{}]interface{} which json cannot encode
+func jsonCompatibleValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		res := make(map[string]interface{})
+		for k, v := range value {
+			res[fmt.Sprintf("%v", k)] = jsonCompatibleValue(v)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(value))
+		for i, v := range value {
+			res[i] = jsonCompatibleValue(v)
+		}
+		return res
+	default:
+		return value
+	}
+}
+
+// yaml.v2 decodes the values but does not expose where they are, so the positions come from the nodes of yaml.v3
+func yamlPathOffsets(input []byte) map[string]int64 {
+	res := make(map[string]int64)
+	var doc yamlnode.Node
+	if err := yamlnode.Unmarshal(input, &doc); err != nil {
+		return res
+	}
+
+	lineOffsets := []int64{0}
+	for i, c := range input {
+		if c == '\n' {
+			lineOffsets = append(lineOffsets, int64(i+1))
+		}
+	}
+	offset := func(node *yamlnode.Node) int64 {
+		if node.Line < 1 || node.Line > len(lineOffsets) {
+			return 0
+		}
+		lineStart := lineOffsets[node.Line-1]
+		line := input[lineStart:]
+		if end := bytes.IndexByte(line, '\n'); end >= 0 {
+			line = line[:end]
+		}
+		// columns count characters, offsets count bytes
+		runes := []rune(string(line))
+		return lineStart + int64(len(string(runes[:minInt(node.Column-1, len(runes))])))
+	}
+
+	var walk func(path string, node *yamlnode.Node)
+	walk = func(path string, node *yamlnode.Node) {
+		switch node.Kind {
+		case yamlnode.DocumentNode:
+			for _, child := range node.Content {
+				walk(path, child)
+			}
+		case yamlnode.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key := node.Content[i]
+				childPath := joinJsonPath(path, key.Value)
+				res[childPath] = offset(key)
+				walk(childPath, node.Content[i+1])
+			}
+		case yamlnode.SequenceNode:
+			for i, child := range node.Content {
+				childPath := fmt.Sprintf("%s[%d]", path, i)
+				res[childPath] = offset(child)
+				walk(childPath, child)
+			}
+		}
+	}
+	walk("", &doc)
+	return res
+}
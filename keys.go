@@ -29,15 +29,15 @@ func commandGenerateTestKeys(requiredOptions []string) {
 		}
 	}
 
-	bytes, err := jsoncodec.MarshalKeys(keys)
-	if err != nil {
-		die("Could not encode keys to json.\n\n%s", err.Error())
-	}
-
 	filename := *flagKeyFile
 	if filename == "" {
 		filename = TEST_KEYS_FILENAME
 	}
+
+	bytes, err := jsoncodec.MarshalKeys(filename, keys)
+	if err != nil {
+		die("Could not encode keys.\n\n%s", err.Error())
+	}
 	err = ioutil.WriteFile(filename, bytes, 0644)
 	if err != nil {
 		die("Could not write keys to file.\n\n%s", err.Error())
@@ -60,9 +60,9 @@ func getTestKeyFromFile(id string) *jsoncodec.RawKey {
 		die("Could not open keys file '%s'.\n\n%s", *flagKeyFile, err.Error())
 	}
 
-	keys, err := jsoncodec.UnmarshalKeys(bytes)
+	keys, err := jsoncodec.UnmarshalKeys(*flagKeyFile, bytes)
 	if err != nil {
-		die("Failed parsing keys json file '%s'. Try deleting the key file to have it automatically recreated.\n\n%s", *flagKeyFile, err.Error())
+		die("Failed parsing keys file '%s'. Try deleting the key file to have it automatically recreated.\n\n%s", *flagKeyFile, err.Error())
 	}
 
 	key, found := keys[id]
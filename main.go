@@ -14,18 +14,22 @@ import (
 )
 
 var GAMMA_CLI_VERSION string
+
 const CONFIG_FILENAME = "orbs-gamma-config.json"
 const TEST_KEYS_FILENAME = "orbs-test-keys.json"
+const ADDRESS_BOOK_FILENAME = "orbs-address-book.json"
+const DEPLOY_LOCK_FILENAME = "orbs-deploy-lock.json"
 const LOCAL_ENV_ID = "local"
 const EXPERIMENTAL_ENV_ID = "experimental"
 
 type handlerOptions struct {
 	name string
 
-	dockerRepo            string
-	dockerCmd             []string
-	containerName         string
-	dockerRegistryTagsUrl string
+	dockerRepo     string
+	dockerTag      string // pinned image tag, the latest one in the registry if empty
+	dockerCmd      []string
+	containerName  string
+	dockerRegistry *dockerRegistry
 
 	env []string
 
@@ -43,28 +47,33 @@ type command struct {
 	handler
 	sort            int
 	requiredOptions []string
+	subcommandArgs  bool // positional args of the subcommand may be interleaved with flags
 }
 
 func gammaHandlerOptions() handlerOptions {
+	image := getDockerImage("GammaImage", GAMMA_DOCKER_REPO, envGammaImage, *flagServerVersion)
 	return handlerOptions{
-		name:                  "Orbs Gamma personal blockchain",
-		dockerRepo:            "orbsnetwork/gamma",
-		dockerCmd:             []string{"./gamma-server", "-override-config", *flagOverrideConfig},
-		containerName:         "orbs-gamma-server",
-		dockerRegistryTagsUrl: "https://registry.hub.docker.com/v2/repositories/orbsnetwork/gamma/tags/",
-		port:                  *flagPort,
-		containerPort:         8080,
+		name:           "Orbs Gamma personal blockchain",
+		dockerRepo:     image.repo,
+		dockerTag:      image.tag,
+		dockerCmd:      []string{"./gamma-server", "-override-config", *flagOverrideConfig},
+		containerName:  "orbs-gamma-server",
+		dockerRegistry: image.registry,
+		port:           *flagPort,
+		containerPort:  8080,
 	}
 }
 
 func prismHandlerOptions() handlerOptions {
+	image := getDockerImage("PrismImage", PRISM_DOCKER_REPO, envPrismImage, "")
 	return handlerOptions{
-		name:                  "Prism blockchain explorer",
-		dockerRepo:            "orbsnetwork/prism",
-		containerName:         "orbs-prism",
-		dockerRegistryTagsUrl: "https://registry.hub.docker.com/v2/repositories/orbsnetwork/prism/tags/",
-		port:                  *flagPrismPort,
-		containerPort:         3000,
+		name:           "Prism blockchain explorer",
+		dockerRepo:     image.repo,
+		dockerTag:      image.tag,
+		containerName:  "orbs-prism",
+		dockerRegistry: image.registry,
+		port:           *flagPrismPort,
+		containerPort:  3000,
 		env: []string{
 			"ORBS_VIRTUAL_CHAIN_ID=42",
 			"NODE_ENV=staging",
@@ -78,7 +87,7 @@ func prismHandlerOptions() handlerOptions {
 var commands = map[string]*command{
 	"start-local": {
 		desc:            "start a local Orbs personal blockchain instance listening on port",
-		args:            "-port <PORT> -override-config {json}",
+		args:            "-port <PORT> -override-config {json|@path.json} -skip-config-validation -server-version [TAG]",
 		example:         "gamma-cli start-local -port 8080",
 		handler:         commandStartLocal,
 		sort:            0,
@@ -100,34 +109,34 @@ var commands = map[string]*command{
 	},
 	"deploy": {
 		desc:            "deploy a smart contract with the code specified in the source file <CODE_FILE>",
-		args:            "<CODE_FILE|CODE_DIR> -name [CONTRACT_NAME] -signer [ID_FROM_KEYS_JSON]",
+		args:            "<CODE_FILE|CODE_DIR> -name [CONTRACT_NAME] -signer [ID_FROM_KEYS_JSON] -processor [native|javascript] -versioned -if-missing -check -precheck -quiet",
 		example:         "gamma-cli deploy MyToken.go -signer user1",
-		example2:        "gamma-cli deploy contract.go -name MyToken",
+		example2:        "gamma-cli deploy contract.go -name MyToken -if-missing",
 		handler:         commandDeploy,
 		sort:            3,
 		requiredOptions: []string{"<CODE_FILE> - path of file with source code"},
 	},
 	"send-tx": {
 		desc:            "sign and send the transaction specified in the JSON file <INPUT_FILE>",
-		args:            "<INPUT_FILE> -arg# [OVERRIDE_ARG_#] -signer [ID_FROM_KEYS_JSON]",
+		args:            "<INPUT_FILE> -arg# [OVERRIDE_ARG_#] -signer [ID_FROM_KEYS_JSON] -abi [ABI_FILE|CODE_FILE] -vars [VARS_FILE] -set [NAME=VALUE] -no-template -allow-local-args",
 		example:         "gamma-cli send-tx transfer.json -signer user1",
 		example2:        "gamma-cli send-tx transfer.json -arg2 0x5B63Ca66637316A0D7f84Ebf60E50963c10059aD",
 		handler:         commandSendTx,
 		sort:            4,
-		requiredOptions: []string{"<INPUT_FILE> - path of JSON file with transaction details"},
+		requiredOptions: []string{"<INPUT_FILE> - path of JSON, YAML or TOML file with transaction details"},
 	},
 	"run-query": {
 		desc:            "read state or run a read-only contract method as specified in the JSON file <INPUT_FILE>",
-		args:            "<INPUT_FILE> -arg# [OVERRIDE_ARG_#] -signer [ID_FROM_KEYS_JSON]",
+		args:            "<INPUT_FILE> -arg# [OVERRIDE_ARG_#] -signer [ID_FROM_KEYS_JSON] -abi [ABI_FILE|CODE_FILE] -vars [VARS_FILE] -set [NAME=VALUE] -no-template -allow-local-args -all-endpoints",
 		example:         "gamma-cli run-query get-balance.json -signer user1",
 		example2:        "gamma-cli run-query get-balance.json -arg1 0x5B63Ca66637316A0D7f84Ebf60E50963c10059aD",
 		handler:         commandRunQuery,
 		sort:            5,
-		requiredOptions: []string{"<INPUT_FILE> - path of JSON file with query details"},
+		requiredOptions: []string{"<INPUT_FILE> - path of JSON, YAML or TOML file with query details"},
 	},
 	"tx-status": {
 		desc:            "get the current status of a sent transaction with txid <TX_ID> (from send-tx response)",
-		args:            "<TX_ID>",
+		args:            "<TX_ID> -all-endpoints",
 		example:         "gamma-cli tx-status 0xB68fa95B7f397815Ddf41150d79b27a888448a22e08DeAf8600E7a495c406303659f8C3782614660",
 		handler:         commandTxStatus,
 		sort:            6,
@@ -162,24 +171,112 @@ var commands = map[string]*command{
 		sort:            10,
 		requiredOptions: nil,
 	},
+	"address": {
+		desc:            "manage the address book of named accounts and inspect addresses",
+		args:            "add <NAME> <ADDRESS> | list | remove <NAME> | from-public-key <PUBLIC_KEY> | validate <ADDRESS> -book [ADDRESS_BOOK_FILE]",
+		example:         "gamma-cli address add exchange 0x5B63Ca66637316A0D7f84Ebf60E50963c10059aD",
+		example2:        "gamma-cli address validate 0x5b63ca66637316a0d7f84ebf60e50963c10059ad",
+		handler:         commandAddress,
+		sort:            11,
+		requiredOptions: []string{"<SUBCOMMAND> - one of add, list, remove, from-public-key, validate"},
+		subcommandArgs:  true,
+	},
+	"contract-abi": {
+		desc:            "print the JSON abi of the methods exported by the contract source in <CODE_FILE>",
+		args:            "<CODE_FILE|CODE_DIR> -name [CONTRACT_NAME]",
+		example:         "gamma-cli contract-abi MyToken.go > MyToken.abi.json",
+		handler:         commandContractAbi,
+		sort:            12,
+		requiredOptions: []string{"<CODE_FILE> - path of file with source code"},
+	},
+	"gen-call": {
+		desc:            "print a JSON input file for send-tx or run-query calling <METHOD> of the contract in <ABI>",
+		args:            "<ABI_FILE|CODE_FILE|CODE_DIR> <METHOD> -name [CONTRACT_NAME]",
+		example:         "gamma-cli gen-call MyToken.abi.json transfer > transfer.json",
+		example2:        "gamma-cli gen-call MyToken.go balanceOf > get-balance.json",
+		handler:         commandGenerateCall,
+		sort:            13,
+		requiredOptions: []string{"<ABI> - path of JSON abi file or contract source code", "<METHOD> - name of exported contract method"},
+	},
+	"deploy-all": {
+		desc:            "deploy all contracts listed in the YAML or JSON manifest <MANIFEST> in dependency order",
+		args:            "<MANIFEST> -lock [LOCK_FILE] -signer [ID_FROM_KEYS_JSON] -allow-local-args -quiet",
+		example:         "gamma-cli deploy-all contracts.yaml",
+		example2:        "gamma-cli deploy-all contracts.json -env testnet -lock testnet-lock.json",
+		handler:         commandDeployAll,
+		sort:            14,
+		requiredOptions: []string{"<MANIFEST> - path of YAML or JSON file listing contracts to deploy"},
+	},
+	"check-contract": {
+		desc:            "type check the contract source in <CODE_FILE> locally and verify the export conventions",
+		args:            "<CODE_FILE|CODE_DIR>",
+		example:         "gamma-cli check-contract MyToken.go",
+		handler:         commandCheckContract,
+		sort:            15,
+		requiredOptions: []string{"<CODE_FILE> - path of file with source code"},
+	},
+	"contract-alias": {
+		desc:            "manage the aliases in the deployment lockfile mapping logical contract names to deployed versions",
+		args:            "list | set <ALIAS> <CONTRACT_NAME> | remove <ALIAS> | resolve <ALIAS> -env [ENVIRONMENT_ID] -lock [LOCK_FILE]",
+		example:         "gamma-cli contract-alias list -env testnet",
+		example2:        "gamma-cli contract-alias set MyToken MyToken_v2",
+		handler:         commandContractAlias,
+		sort:            16,
+		requiredOptions: []string{"<SUBCOMMAND> - one of list, set, remove, resolve"},
+		subcommandArgs:  true,
+	},
+	"config": {
+		desc:            "manage the config files, show lists the files found or the effective values of the environment with -resolved, editing commands rewrite the file without its comments and key order",
+		args:            "show -resolved | init | add-env <ENVIRONMENT_ID> -endpoint [URL] -vchain [ID] | remove-env <ENVIRONMENT_ID> | use <ENVIRONMENT_ID> | validate",
+		example:         "gamma-cli config add-env testnet -endpoint https://node1.example.com -vchain 1000",
+		example2:        "gamma-cli config show -resolved -env testnet",
+		handler:         commandConfig,
+		sort:            17,
+		requiredOptions: []string{"<SUBCOMMAND> - one of show, init, add-env, remove-env, use, validate"},
+		subcommandArgs:  true,
+	},
 	"help": {
 		desc:            "print this help screen",
-		sort:            11,
+		sort:            18,
 		requiredOptions: nil,
 	},
 }
 
 var (
-	flagPort           = flag.Int("port", 8080, "listening port for Gamma server")
-	flagPrismPort      = flag.Int("prismPort", 3000, "listening port for Prism blockchain explorer")
-	flagSigner         = flag.String("signer", "user1", "id of the signing key from the test key json")
-	flagContractName   = flag.String("name", "", "name of the smart contract being deployed")
-	flagKeyFile        = flag.String("keys", TEST_KEYS_FILENAME, "name of the json file containing test keys")
-	flagConfigFile     = flag.String("config", CONFIG_FILENAME, "path to config file")
-	flagEnv            = flag.String("env", LOCAL_ENV_ID, "environment from config file containing server connection details")
-	flagWait           = flag.Bool("wait", false, "wait until Gamma server is ready and listening")
-	flagNoUi           = flag.Bool("no-ui", false, "do not start Prism blockchain explorer")
-	flagOverrideConfig = flag.String("override-config", "{}", "option json for overriding config values, same format as file-based config")
+	flagPort            = flag.Int("port", 8080, "listening port for Gamma server")
+	flagPrismPort       = flag.Int("prismPort", 3000, "listening port for Prism blockchain explorer, the PrismPort of the environment if omitted")
+	flagSigner          = flag.String("signer", "user1", "id of the signing key from the test key json, the Signer of the environment if omitted")
+	flagContractName    = flag.String("name", "", "name of the smart contract being deployed")
+	flagKeyFile         = flag.String("keys", TEST_KEYS_FILENAME, "name of the json, yaml or toml file containing test keys, the Keys of the environment if omitted")
+	flagAddressBook     = flag.String("book", ADDRESS_BOOK_FILENAME, "name of the json file containing the address book")
+	flagAbi             = flag.String("abi", "", "path of JSON abi file or contract source code to validate send-tx and run-query input against")
+	flagProcessor       = flag.String("processor", "", "processor of the deployed contract (native or javascript), detected from the source file extension if omitted")
+	flagDeployVersioned = flag.Bool("versioned", false, "deploy as the next version of the contract (eg. MyToken_v2) and point its alias in the lockfile to it")
+	flagDeployIfMissing = flag.Bool("if-missing", false, "deploy only if a contract with the same name is not already deployed")
+	flagDeployCheck     = flag.Bool("check", false, "compare the deployed contract with the local sources instead of deploying (identical, different or missing)")
+	flagDeployPrecheck  = flag.Bool("precheck", false, "type check the contract sources locally before signing the deploy transaction")
+	flagDeployLock      = flag.String("lock", DEPLOY_LOCK_FILENAME, "name of the json lockfile recording deployed contracts")
+	flagConfigFile      = flag.String("config", CONFIG_FILENAME, "path to config file, searched in the current and parent directories, $XDG_CONFIG_HOME/gamma and ~/.orbs if omitted")
+	flagConfigResolved  = flag.Bool("resolved", false, "show the effective values of the environment and where each one is set")
+	flagVirtualChain    = flag.Uint("vchain", 0, "virtual chain id of the environment added by config add-env")
+	flagEnv             = flag.String("env", LOCAL_ENV_ID, "environment from config file containing server connection details, GAMMA_ENV if omitted")
+	flagAllEndpoints    = flag.Bool("all-endpoints", false, "send the request to every endpoint of the environment and report nodes with divergent responses")
+	flagYes             = flag.Bool("yes", false, "do not ask for confirmation before sending transactions to a main net environment")
+	flagRoundRobin      = flag.Bool("round-robin", false, "spread queries over all the endpoints of the environment instead of sending them to the first reachable one")
+	flagWait            = flag.Bool("wait", false, "wait until Gamma server is ready and listening")
+	flagNoUi            = flag.Bool("no-ui", false, "do not start Prism blockchain explorer")
+	flagQuiet           = flag.Bool("quiet", false, "do not list the contract source files bundled on deploy")
+	flagUint256Output   = flag.String("uint256-output", "hex", "format of uint256 output values: hex, decimal, a unit (eg. ether) or a number of decimals (eg. 18), written with their unit or exponent so they read back as input")
+	flagUint256Units    = flag.String("uint256-units", "", "additional unit suffixes for uint256 input values as comma separated name=decimals (eg. token=8)")
+	flagAllowLocalArgs  = flag.Bool("allow-local-args", false, "allow gamma:env and gamma:file-bytes/file-string arguments to read environment variables and files into the transaction")
+	flagVarsFile        = flag.String("vars", "", "path of a json file with values of ${NAME} variables in input files")
+	flagNoTemplate      = flag.Bool("no-template", false, "use input files as is instead of expanding their ${NAME} variables (a literal ${ can also be written $${)")
+	flagOverrideConfig  = flag.String("override-config", "{}", "option json for overriding config values, same format as file-based config, or @path.json to read it from a file")
+	flagSkipConfigCheck = flag.Bool("skip-config-validation", false, "pass -override-config keys unknown to gamma-cli to the server as is (eg. keys of a newer Gamma server) instead of failing")
+	flagServerVersion   = flag.String("server-version", "", "tag of the Gamma server image to run (eg. v1.3.0), the GammaImage Tag of the environment or the latest version if omitted")
+
+	flagSetVars         stringListFlag
+	flagConfigEndpoints stringListFlag
 
 	// args (hidden from help)
 	flagArg1 = flag.String("arg1", "", "")
@@ -196,6 +293,8 @@ var (
 func main() {
 	flag.Usage = func() { commandShowHelp(nil) }
 	commands["help"].handler = commandShowHelp
+	flag.Var(&flagSetVars, "set", "value of a ${NAME} variable in input files as NAME=VALUE, may be repeated")
+	flag.Var(&flagConfigEndpoints, "endpoint", "endpoint of the environment added by config add-env, may be repeated or comma separated")
 
 	if len(os.Args) <= 1 {
 		commandShowHelp(nil)
@@ -219,10 +318,41 @@ func main() {
 		}
 	}
 
-	os.Args = os.Args[2+len(cmd.requiredOptions)-1:]
-	flag.Parse()
+	positionalArgs := parseCommandFlags(flag.CommandLine, cmd, os.Args[2+len(cmd.requiredOptions):])
+	configureEnvironment()
+	getArgsOptions() // fails on invalid codec flags before any request is sent
+	configureTemplateVars()
+
+	cmd.handler(append(requiredOptions, positionalArgs...))
+}
+
+// flags of other commands end at the first positional arg, which is ignored with the args after it
+func parseCommandFlags(flags *flag.FlagSet, cmd *command, args []string) []string {
+	if !cmd.subcommandArgs {
+		flags.Parse(args)
+		return nil
+	}
+	return parseFlagsAndPositionalArgs(flags, args)
+}
+
+// allows positional args to be interleaved with flags (eg. for subcommands)
+func parseFlagsAndPositionalArgs(flags *flag.FlagSet, args []string) []string {
+	var positionalArgs []string
+	for {
+		flags.Parse(args)
+		args = flags.Args()
+		if len(args) == 0 {
+			return positionalArgs
+		}
+		positionalArgs = append(positionalArgs, args[0])
+		args = args[1:]
+	}
+}
 
-	cmd.handler(requiredOptions)
+func requireSubcommandArgs(subcommand string, args []string, names ...string) {
+	if len(args) < len(names) {
+		die("Command '%s' is missing required arguments %v.", subcommand, names)
+	}
 }
 
 func log(format string, args ...interface{}) {
@@ -0,0 +1,39 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"flag"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseCommandFlags(t *testing.T) {
+	tests := []struct {
+		command        string
+		args           []string
+		expectedArgs   []string
+		expectedSigner string
+	}{
+		{"deploy", []string{"-name", "MyToken", "-signer", "user2"}, nil, "user2"},
+		{"deploy", []string{"-name", "MyToken", "extra", "-signer", "user2"}, nil, "user1"},
+		{"send-tx", []string{"extra", "-signer", "user2"}, nil, "user1"},
+		{"address", []string{"exchange", "-signer", "user2", "0x5B63Ca66637316A0D7f84Ebf60E50963c10059aD"}, []string{"exchange", "0x5B63Ca66637316A0D7f84Ebf60E50963c10059aD"}, "user2"},
+		{"config", []string{"-signer", "user2", "testnet"}, []string{"testnet"}, "user2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			flags := flag.NewFlagSet(tt.command, flag.ContinueOnError)
+			flags.String("name", "", "")
+			signer := flags.String("signer", "user1", "")
+
+			args := parseCommandFlags(flags, commands[tt.command], tt.args)
+			require.Equal(t, tt.expectedArgs, args, "only subcommands should take interleaved positional args")
+			require.Equal(t, tt.expectedSigner, *signer, "flags after a positional arg should be parsed only for subcommands")
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/orbs-network/gamma-cli/jsoncodec"
+	"github.com/orbs-network/orbs-client-sdk-go/codec"
+	"github.com/pkg/errors"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const (
+	NETWORK_TYPE_MAIN = "main"
+	NETWORK_TYPE_TEST = "test"
+)
+
+var mainNetConfirmed = false
+
+func getNetworkType(envId string, env *jsoncodec.ConfEnv) codec.NetworkType {
+	networkType, err := parseNetworkType(envId, env)
+	if err != nil {
+		die("Invalid NetworkType of environment '%s' in '%s'.\n\n%s", envId, getConfigSource("NetworkType"), err.Error())
+	}
+	return networkType
+}
+
+// environments default to test net, the local Gamma server and the experimental environment always are
+func parseNetworkType(envId string, env *jsoncodec.ConfEnv) (codec.NetworkType, error) {
+	switch strings.ToLower(env.NetworkType) {
+	case "", NETWORK_TYPE_TEST:
+		return codec.NETWORK_TYPE_TEST_NET, nil
+	case NETWORK_TYPE_MAIN:
+		if envId == LOCAL_ENV_ID || envId == EXPERIMENTAL_ENV_ID {
+			return "", errors.Errorf("environment '%s' runs on the local Gamma server which is a test net", envId)
+		}
+		for _, endpoint := range env.Endpoints {
+			if isLoopbackEndpoint(endpoint) {
+				return "", errors.Errorf("endpoint '%s' is the local Gamma server which is a test net", endpoint)
+			}
+		}
+		return codec.NETWORK_TYPE_MAIN_NET, nil
+	default:
+		return "", errors.Errorf("NetworkType should be '%s' or '%s', current value: '%s'", NETWORK_TYPE_MAIN, NETWORK_TYPE_TEST, env.NetworkType)
+	}
+}
+
+// 'localhost' or an url of this machine (eg. http://localhost:8080 or http://127.0.0.1:8080)
+func isLoopbackEndpoint(endpoint string) bool {
+	if endpoint == "localhost" {
+		return true
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && (ip.IsLoopback() || ip.IsUnspecified())
+}
+
+// transactions signed for main net are production operations, so they are confirmed once per run (or by -yes)
+func requireMainNetConfirmation(client *gammaClient, action string) {
+	if client.NetworkType != codec.NETWORK_TYPE_MAIN_NET || *flagYes || mainNetConfirmed {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "Environment '%s' is a main net, about to %s.\nType 'yes' to continue: ", *flagEnv, action)
+	confirmed, err := readConfirmation(os.Stdin)
+	if err != nil {
+		die("\nRefusing to %s on main net environment '%s' without confirmation, use -yes to confirm non-interactively.", action, *flagEnv)
+	}
+	if !confirmed {
+		die("Aborted, nothing was sent to environment '%s'.", *flagEnv)
+	}
+	mainNetConfirmed = true
+}
+
+func readConfirmation(reader io.Reader) (bool, error) {
+	answer, err := bufio.NewReader(reader).ReadString('\n')
+	if err != nil && (err != io.EOF || answer == "") {
+		return false, err
+	}
+	return strings.ToLower(strings.TrimSpace(answer)) == "yes", nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"github.com/orbs-network/gamma-cli/jsoncodec"
+	"github.com/orbs-network/orbs-client-sdk-go/codec"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestParseNetworkType(t *testing.T) {
+	tests := []struct {
+		name      string
+		envId     string
+		env       *jsoncodec.ConfEnv
+		expected  codec.NetworkType
+		expectErr bool
+	}{
+		{"DefaultsToTest", "staging", &jsoncodec.ConfEnv{Endpoints: []string{"http://node1"}}, codec.NETWORK_TYPE_TEST_NET, false},
+		{"Test", "staging", &jsoncodec.ConfEnv{NetworkType: "test", Endpoints: []string{"http://node1"}}, codec.NETWORK_TYPE_TEST_NET, false},
+		{"Main", "production", &jsoncodec.ConfEnv{NetworkType: "Main", Endpoints: []string{"http://node1"}}, codec.NETWORK_TYPE_MAIN_NET, false},
+		{"Unknown", "production", &jsoncodec.ConfEnv{NetworkType: "mainnet", Endpoints: []string{"http://node1"}}, "", true},
+		{"MainOnLocalEnv", LOCAL_ENV_ID, &jsoncodec.ConfEnv{NetworkType: "main", Endpoints: []string{"http://node1"}}, "", true},
+		{"MainOnLocalhost", "production", &jsoncodec.ConfEnv{NetworkType: "main", Endpoints: []string{"http://node1", "localhost"}}, "", true},
+		{"MainOnLocalhostUrl", "production", &jsoncodec.ConfEnv{NetworkType: "main", Endpoints: []string{"http://node1", "http://localhost:8080"}}, "", true},
+		{"MainOnLoopbackIp", "production", &jsoncodec.ConfEnv{NetworkType: "main", Endpoints: []string{"http://127.0.0.1:8080"}}, "", true},
+		{"MainOnLoopbackIpv6", "production", &jsoncodec.ConfEnv{NetworkType: "main", Endpoints: []string{"http://[::1]:8080"}}, "", true},
+		{"MainOnHostNamedLocal", "production", &jsoncodec.ConfEnv{NetworkType: "main", Endpoints: []string{"https://localhost-proxy.example.com"}}, codec.NETWORK_TYPE_MAIN_NET, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			networkType, err := parseNetworkType(tt.envId, tt.env)
+			require.Equal(t, tt.expectErr, err != nil, "unexpected error %v", err)
+			require.Equal(t, tt.expected, networkType)
+		})
+	}
+}
+
+func TestReadConfirmation(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  bool
+		expectErr bool
+	}{
+		{"yes\n", true, false},
+		{" YES \n", true, false},
+		{"yes", true, false},
+		{"y\n", false, false},
+		{"\n", false, false},
+		{"", false, true},
+	}
+	for _, tt := range tests {
+		confirmed, err := readConfirmation(strings.NewReader(tt.input))
+		require.Equal(t, tt.expectErr, err != nil, "unexpected error %v for input %q", err, tt.input)
+		require.Equal(t, tt.expected, confirmed, "input %q", tt.input)
+	}
+}
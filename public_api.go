@@ -10,16 +10,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/orbs-network/gamma-cli/jsoncodec"
-	"github.com/orbs-network/orbs-client-sdk-go/codec"
 	"github.com/orbs-network/orbs-client-sdk-go/orbs"
 	"github.com/pkg/errors"
 	"io/ioutil"
-	"net"
-	"net/url"
 	"os"
 	"path"
+	"strconv"
 	"strings"
-	"syscall"
 )
 
 const DEPLOY_SYSTEM_CONTRACT_NAME = "_Deployments"
@@ -28,18 +25,52 @@ const PROCESSOR_TYPE_NATIVE = uint32(1)
 const PROCESSOR_TYPE_JAVASCRIPT = uint32(2)
 
 func _getSource(name string) (code [][]byte, err error) {
-	if info, err := os.Stat(name); err != nil {
+	filenames, err := _getSourceFilenames(name)
+	if err != nil {
 		return nil, err
-	} else if info.IsDir() {
-		return orbs.ReadSourcesFromDir(name)
-	} else {
-		singleFile, err := ioutil.ReadFile(name)
+	}
+
+	for _, filename := range filenames {
+		source, err := ioutil.ReadFile(filename)
 		if err != nil {
 			return nil, err
 		}
+		code = append(code, source)
+	}
+	return code, nil
+}
+
+// a single file is returned as is, a dir returns its .go (non _test.go) or .js files sorted by name, never both
+func _getSourceFilenames(name string) ([]string, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{name}, nil
+	}
 
-		return [][]byte{singleFile}, err
+	files, err := ioutil.ReadDir(name)
+	if err != nil {
+		return nil, err
 	}
+
+	var res []string
+	extensions := make(map[string]bool)
+	for _, f := range files {
+		if f.IsDir() || strings.HasSuffix(f.Name(), "_test.go") {
+			continue
+		}
+		ext := path.Ext(f.Name())
+		if ext == ".go" || ext == ".js" {
+			res = append(res, path.Join(name, f.Name()))
+			extensions[ext] = true
+		}
+	}
+	if len(extensions) > 1 {
+		return nil, errors.Errorf("directory '%s' mixes .go and .js files, a contract must be written in a single language", name)
+	}
+	return res, nil
 }
 
 func commandDeploy(requiredOptions []string) {
@@ -49,24 +80,48 @@ func commandDeploy(requiredOptions []string) {
 		*flagContractName = getFilenameWithoutExtension(codeFile)
 	}
 
-	code, err := _getSource(codeFile)
+	filenames, err := _getSourceFilenames(codeFile)
 	if err != nil {
 		die("Could not find path\n\n%s", err.Error())
 	}
+	if len(filenames) == 0 {
+		die("No contract source files found in '%s'.", codeFile)
+	}
+	processorType := getProcessorType(*flagProcessor, filenames)
+
+	if *flagDeployPrecheck {
+		precheckContract(codeFile, processorType)
+	}
+
+	code := getContractCode(codeFile, filenames, processorType)
 
 	signer := getTestKeyFromFile(*flagSigner)
 
 	client := createOrbsClient()
 
-	payload, txId, err := client.CreateDeployTransaction(signer.PublicKey, signer.PrivateKey, string(*flagContractName), orbs.PROCESSOR_TYPE_NATIVE, code...)
+	if *flagDeployVersioned {
+		commandDeployVersioned(client, signer, processorType, code)
+	}
+
+	if *flagDeployCheck {
+		commandDeployCheck(client, signer, *flagContractName, code)
+	}
+
+	if *flagDeployIfMissing && isContractDeployed(client, signer, *flagContractName) {
+		log("Contract '%s' is already deployed, skipping.", *flagContractName)
+		exit()
+	}
+
+	requireMainNetConfirmation(client, "deploy contract '"+*flagContractName+"'")
+	payload, txId, err := client.CreateDeployTransaction(signer.PublicKey, signer.PrivateKey, string(*flagContractName), orbs.ProcessorType(processorType), code...)
 	if err != nil {
 		die("Could not encode payload of the message about to be sent to server.\n\n%s", err.Error())
 	}
 
-	response, clientErr := client.SendTransaction(payload)
+	response, clientErr := client.SendTransaction(payload, txId)
 	handleNoConnectionGracefully(clientErr, client)
 	if response != nil {
-		output, err := jsoncodec.MarshalSendTxResponse(response, txId)
+		output, err := jsoncodec.MarshalSendTxResponse(response, txId, getArgsOptions())
 		if err != nil {
 			die("Could not encode send-tx response to json.\n\n%s", err.Error())
 		}
@@ -90,33 +145,39 @@ func commandSendTx(requiredOptions []string) {
 		die("Could not open input file.\n\n%s", err.Error())
 	}
 
-	sendTx, err := jsoncodec.UnmarshalSendTx(bytes)
+	sendTx, err := jsoncodec.UnmarshalSendTx(inputFile, bytes)
 	if err != nil {
-		die("Failed parsing input json file '%s'.\n\n%s", inputFile, err.Error())
+		die("Failed parsing input file '%s'.\n\n%s", inputFile, err.Error())
 	}
 
 	// override contract name
 	if *flagContractName != "" {
 		sendTx.ContractName = *flagContractName
 	}
+	if err := jsoncodec.RequireCallNames(inputFile, sendTx.ContractName, sendTx.MethodName); err != nil {
+		die("Failed parsing input file '%s'.\n\n%s", inputFile, err.Error())
+	}
 
 	overrideArgsWithFlags(sendTx.Arguments)
-	inputArgs, err := jsoncodec.UnmarshalArgs(sendTx.Arguments, getTestKeyFromFile)
+	sendTx.ContractName = resolveContractAlias(sendTx.ContractName)
+	validateInputAgainstAbi(sendTx.ContractName, sendTx.MethodName, sendTx.Arguments)
+	inputArgs, err := jsoncodec.UnmarshalArgs(sendTx.Arguments, getTestKeyFromFile, getAddressFromBook, getArgsOptions())
 	if err != nil {
 		die(err.Error())
 	}
 
 	client := createOrbsClient()
 
+	requireMainNetConfirmation(client, "send transaction '"+sendTx.MethodName+"' of contract '"+sendTx.ContractName+"'")
 	payload, txId, err := client.CreateTransaction(signer.PublicKey, signer.PrivateKey, sendTx.ContractName, sendTx.MethodName, inputArgs...)
 	if err != nil {
 		die("Could not encode payload of the message about to be sent to server.\n\n%s", err.Error())
 	}
 
-	response, clientErr := client.SendTransaction(payload)
+	response, clientErr := client.SendTransaction(payload, txId)
 	handleNoConnectionGracefully(clientErr, client)
 	if response != nil {
-		output, err := jsoncodec.MarshalSendTxResponse(response, txId)
+		output, err := jsoncodec.MarshalSendTxResponse(response, txId, getArgsOptions())
 		if err != nil {
 			die("Could not encode send-tx response to json.\n\n%s", err.Error())
 		}
@@ -140,18 +201,23 @@ func commandRunQuery(requiredOptions []string) {
 		die("Could not open input file.\n\n%s", err.Error())
 	}
 
-	runQuery, err := jsoncodec.UnmarshalRead(bytes)
+	runQuery, err := jsoncodec.UnmarshalRead(inputFile, bytes)
 	if err != nil {
-		die("Failed parsing input json file '%s'.\n\n%s", inputFile, err.Error())
+		die("Failed parsing input file '%s'.\n\n%s", inputFile, err.Error())
 	}
 
 	// override contract name
 	if *flagContractName != "" {
 		runQuery.ContractName = *flagContractName
 	}
+	if err := jsoncodec.RequireCallNames(inputFile, runQuery.ContractName, runQuery.MethodName); err != nil {
+		die("Failed parsing input file '%s'.\n\n%s", inputFile, err.Error())
+	}
 
 	overrideArgsWithFlags(runQuery.Arguments)
-	inputArgs, err := jsoncodec.UnmarshalArgs(runQuery.Arguments, getTestKeyFromFile)
+	runQuery.ContractName = resolveContractAlias(runQuery.ContractName)
+	validateInputAgainstAbi(runQuery.ContractName, runQuery.MethodName, runQuery.Arguments)
+	inputArgs, err := jsoncodec.UnmarshalArgs(runQuery.Arguments, getTestKeyFromFile, getAddressFromBook, getArgsOptions())
 	if err != nil {
 		die(err.Error())
 	}
@@ -163,10 +229,14 @@ func commandRunQuery(requiredOptions []string) {
 		die("Could not encode payload of the message about to be sent to server.\n\n%s", err.Error())
 	}
 
+	if *flagAllEndpoints {
+		commandAllEndpoints(client, queryEndpointRequest(payload))
+	}
+
 	response, clientErr := client.SendQuery(payload)
 	handleNoConnectionGracefully(clientErr, client)
 	if response != nil {
-		output, err := jsoncodec.MarshalReadResponse(response)
+		output, err := jsoncodec.MarshalReadResponse(response, getArgsOptions())
 		if err != nil {
 			die("Could not encode run-query response to json.\n\n%s", err.Error())
 		}
@@ -185,10 +255,14 @@ func commandTxStatus(requiredOptions []string) {
 
 	client := createOrbsClient()
 
+	if *flagAllEndpoints {
+		commandAllEndpoints(client, txStatusEndpointRequest(txId))
+	}
+
 	response, clientErr := client.GetTransactionStatus(txId)
 	handleNoConnectionGracefully(clientErr, client)
 	if response != nil {
-		output, err := jsoncodec.MarshalTxStatusResponse(response)
+		output, err := jsoncodec.MarshalTxStatusResponse(response, getArgsOptions())
 		if err != nil {
 			die("Could not encode status response to json.\n\n%s", err.Error())
 		}
@@ -210,7 +284,7 @@ func commandTxProof(requiredOptions []string) {
 	response, clientErr := client.GetTransactionReceiptProof(txId)
 	handleNoConnectionGracefully(clientErr, client)
 	if response != nil {
-		output, err := jsoncodec.MarshalTxProofResponse(response)
+		output, err := jsoncodec.MarshalTxProofResponse(response, getArgsOptions())
 		if err != nil {
 			die("Could not encode tx proof response to json.\n\n%s", err.Error())
 		}
@@ -224,24 +298,28 @@ func commandTxProof(requiredOptions []string) {
 	}
 }
 
-func createOrbsClient() *orbs.OrbsClient {
+func createOrbsClient() *gammaClient {
 	env := getEnvironmentFromConfigFile(*flagEnv)
 	if len(env.Endpoints) == 0 {
 		die("Environment Endpoints key does not contain any endpoints.")
 	}
 
-	endpoint := env.Endpoints[0]
-	if endpoint == "localhost" {
-		if !isDockerContainerRunning(gammaHandlerOptions().containerName) && !isPortListening(gammaHandlerOptions().port) {
-			die("Local Gamma server is not running, use 'gamma-cli start-local' to start it.")
+	var endpoints []string
+	for _, endpoint := range env.Endpoints {
+		if endpoint == "localhost" {
+			if len(env.Endpoints) == 1 && !isDockerContainerRunning(gammaHandlerOptions().containerName) && !isPortListening(gammaHandlerOptions().port) {
+				die("Local Gamma server is not running, use 'gamma-cli start-local' to start it.")
+			}
+			endpoint = fmt.Sprintf("http://localhost:%d", gammaHandlerOptions().port)
 		}
-		endpoint = fmt.Sprintf("http://localhost:%d", gammaHandlerOptions().port)
+		endpoints = append(endpoints, endpoint)
 	}
 
-	return orbs.NewClient(endpoint, env.VirtualChain, codec.NETWORK_TYPE_TEST_NET)
+	configureHttpClient(env.Http, endpoints)
+
+	return newGammaClient(endpoints, env.VirtualChain, getNetworkType(*flagEnv, env), *flagRoundRobin, getRetryPolicy(env.Retry))
 }
 
-// Will get to it when we implement JS
 func getProcessorTypeFromFilename(filename string) uint32 {
 	if strings.HasSuffix(filename, ".go") {
 		return PROCESSOR_TYPE_NATIVE
@@ -253,30 +331,54 @@ func getProcessorTypeFromFilename(filename string) uint32 {
 	return 0
 }
 
-// TODO: this needs to be simplified
-func handleNoConnectionGracefully(err error, client *orbs.OrbsClient) {
-	msg := fmt.Sprintf("Cannot connect to server at endpoint %s\n\nPlease check that:\n - The server is started and running (if just started, may need a second to initialize).\n - The server is accessible over the network.\n - The endpoint is properly configured if a config file is used.", client.Endpoint)
-	switch err := errors.Cause(err).(type) {
-	case *url.Error:
-		die(msg)
-	case *net.OpError:
-		if err.Op == "dial" || err.Op == "read" {
-			die(msg)
-		}
-	case net.Error:
-		if err.Timeout() {
-			die(msg)
-		}
-	case syscall.Errno:
-		if err == syscall.ECONNREFUSED {
-			die(msg)
-		}
-	default:
-		if err == orbs.NoConnectionError {
-			die(msg)
-		}
+// an explicit processor name (-processor flag) takes precedence over the extension of the source files
+func getProcessorType(processorName string, filenames []string) uint32 {
+	switch strings.ToLower(processorName) {
+	case "":
+		return getProcessorTypeFromFilename(filenames[0])
+	case "native", "go":
+		return PROCESSOR_TYPE_NATIVE
+	case "javascript", "js":
+		return PROCESSOR_TYPE_JAVASCRIPT
+	}
+	die("Unsupported processor '%s'.\n\nSupported processors are: native javascript", processorName)
+	return 0
+}
+
+func handleNoConnectionGracefully(err error, client *gammaClient) {
+	if !isConnectionError(err) {
 		return
 	}
+
+	endpoints := fmt.Sprintf("endpoint %s", client.Endpoint)
+	if len(client.endpoints) > 1 {
+		endpoints = fmt.Sprintf("any of the endpoints %s", strings.Join(client.endpoints, " "))
+	}
+	die("Cannot connect to server at %s\n\nPlease check that:\n - The server is started and running (if just started, may need a second to initialize).\n - The server is accessible over the network.\n - The endpoint is properly configured if a config file is used.", endpoints)
+}
+
+// the argument codec options of the -uint256-output, -uint256-units and -allow-local-args flags
+func getArgsOptions() *jsoncodec.ArgsOptions {
+	opts := &jsoncodec.ArgsOptions{Uint256Output: *flagUint256Output, AllowLocalReads: *flagAllowLocalArgs}
+	if *flagUint256Units != "" {
+		opts.Uint256Units = make(map[string]int)
+		for _, unit := range strings.Split(*flagUint256Units, ",") {
+			parts := strings.SplitN(unit, "=", 2)
+			if len(parts) != 2 {
+				die("Option -uint256-units should contain comma separated name=decimals pairs.\n\nCurrent value: '%s'", *flagUint256Units)
+			}
+			decimals, err := strconv.Atoi(parts[1])
+			if err != nil || decimals < 0 {
+				die("Unit '%s' in option -uint256-units should have a non-negative number of decimals.\n\nCurrent value: '%s'", parts[0], parts[1])
+			}
+			opts.Uint256Units[strings.TrimSpace(parts[0])] = decimals
+		}
+	}
+
+	if err := opts.Validate(); err != nil {
+		die("Invalid option -uint256-output or -uint256-units.\n\n%s", err.Error())
+	}
+	return opts
 }
 
 func getFilenameWithoutExtension(filename string) string {
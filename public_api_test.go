@@ -0,0 +1,65 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func writeTempContractDir(t *testing.T, filenames ...string) string {
+	dir, err := ioutil.TempDir("", "gamma-cli-contract")
+	require.NoError(t, err, "temp dir should be created")
+	for _, filename := range filenames {
+		require.NoError(t, ioutil.WriteFile(path.Join(dir, filename), []byte("// "+filename), 0644), "source file should be written")
+	}
+	return dir
+}
+
+func TestGetSourceFilenames(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     []string
+		expected  []string
+		processor uint32
+	}{
+		{"Go", []string{"b.go", "a.go", "a_test.go", "README.md"}, []string{"a.go", "b.go"}, PROCESSOR_TYPE_NATIVE},
+		{"JavaScript", []string{"token.js", "lib.js", "package.json"}, []string{"lib.js", "token.js"}, PROCESSOR_TYPE_JAVASCRIPT},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeTempContractDir(t, tt.files...)
+			defer os.RemoveAll(dir)
+
+			filenames, err := _getSourceFilenames(dir)
+			require.NoError(t, err, "source filenames should be listed")
+
+			var expected []string
+			for _, filename := range tt.expected {
+				expected = append(expected, path.Join(dir, filename))
+			}
+			require.Equal(t, expected, filenames, "only contract sources should be listed, sorted by name")
+			require.Equal(t, tt.processor, getProcessorType("", filenames), "processor should be detected from the file extension")
+		})
+	}
+}
+
+func TestGetSourceFilenames_MixedLanguages(t *testing.T) {
+	dir := writeTempContractDir(t, "token.go", "token.js")
+	defer os.RemoveAll(dir)
+
+	_, err := _getSourceFilenames(dir)
+	require.Error(t, err, "a dir with both .go and .js files should be rejected")
+}
+
+func TestGetProcessorType_Explicit(t *testing.T) {
+	require.Equal(t, PROCESSOR_TYPE_JAVASCRIPT, getProcessorType("javascript", []string{"token.go"}), "explicit processor should take precedence")
+	require.Equal(t, PROCESSOR_TYPE_NATIVE, getProcessorType("native", []string{"token.js"}), "explicit processor should take precedence")
+}
@@ -0,0 +1,96 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"github.com/orbs-network/gamma-cli/jsoncodec"
+	"github.com/pkg/errors"
+	"math/rand"
+	"time"
+)
+
+const (
+	DEFAULT_RETRY_MAX_ATTEMPTS = 3 // when the environment has Retry settings, without them every request is sent once
+	DEFAULT_RETRY_BACKOFF      = 500 * time.Millisecond
+	DEFAULT_RETRY_MAX_BACKOFF  = 5 * time.Second
+	DEFAULT_RETRY_JITTER       = 0.2
+)
+
+var retryJitter = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+type retryPolicy struct {
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	jitter      float64
+}
+
+func getRetryPolicy(conf *jsoncodec.ConfRetry) *retryPolicy {
+	policy, err := newRetryPolicy(conf)
+	if err != nil {
+		die("Invalid Retry settings of environment '%s' in '%s'.\n\n%s", *flagEnv, getConfigSource("Retry"), err.Error())
+	}
+	return policy
+}
+
+// retries are opt-in, a retried transaction may already have reached the network before the connection failed
+func newRetryPolicy(conf *jsoncodec.ConfRetry) (*retryPolicy, error) {
+	policy := &retryPolicy{
+		maxAttempts: 1,
+		backoff:     DEFAULT_RETRY_BACKOFF,
+		maxBackoff:  DEFAULT_RETRY_MAX_BACKOFF,
+		jitter:      DEFAULT_RETRY_JITTER,
+	}
+	if conf == nil {
+		return policy, nil
+	}
+
+	policy.maxAttempts = DEFAULT_RETRY_MAX_ATTEMPTS
+
+	if conf.MaxAttempts < 0 {
+		return nil, errors.Errorf("MaxAttempts should be at least 1, current value: %d", conf.MaxAttempts)
+	}
+	if conf.MaxAttempts > 0 {
+		policy.maxAttempts = conf.MaxAttempts
+	}
+	if conf.Backoff != "" {
+		backoff, err := time.ParseDuration(conf.Backoff)
+		if err != nil || backoff < 0 {
+			return nil, errors.Errorf("Backoff should be a duration (eg. 500ms), current value: '%s'", conf.Backoff)
+		}
+		policy.backoff = backoff
+	}
+	if conf.MaxBackoff != "" {
+		maxBackoff, err := time.ParseDuration(conf.MaxBackoff)
+		if err != nil || maxBackoff < 0 {
+			return nil, errors.Errorf("MaxBackoff should be a duration (eg. 5s), current value: '%s'", conf.MaxBackoff)
+		}
+		policy.maxBackoff = maxBackoff
+	}
+	if policy.maxBackoff < policy.backoff {
+		policy.maxBackoff = policy.backoff
+	}
+	if conf.Jitter != nil {
+		if *conf.Jitter < 0 || *conf.Jitter > 1 {
+			return nil, errors.Errorf("Jitter should be between 0 and 1, current value: %v", *conf.Jitter)
+		}
+		policy.jitter = *conf.Jitter
+	}
+	return policy, nil
+}
+
+// exponential backoff capped at maxBackoff, with up to jitter of it randomized so concurrent clients do not retry in lockstep
+func (p *retryPolicy) delay(retry int) time.Duration {
+	delay := p.backoff
+	for i := 1; i < retry && delay < p.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.maxBackoff {
+		delay = p.maxBackoff
+	}
+	return delay - time.Duration(p.jitter*retryJitter.Float64()*float64(delay))
+}
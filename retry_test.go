@@ -0,0 +1,62 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"github.com/orbs-network/gamma-cli/jsoncodec"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestNewRetryPolicy(t *testing.T) {
+	noJitter := 0.0
+	tooMuchJitter := 1.5
+
+	policy, err := newRetryPolicy(nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, policy.maxAttempts, "requests should not be retried without Retry settings")
+
+	policy, err = newRetryPolicy(&jsoncodec.ConfRetry{})
+	require.NoError(t, err)
+	require.Equal(t, &retryPolicy{DEFAULT_RETRY_MAX_ATTEMPTS, DEFAULT_RETRY_BACKOFF, DEFAULT_RETRY_MAX_BACKOFF, DEFAULT_RETRY_JITTER}, policy, "omitted settings should use the defaults")
+
+	policy, err = newRetryPolicy(&jsoncodec.ConfRetry{MaxAttempts: 5, Backoff: "100ms", MaxBackoff: "1s", Jitter: &noJitter})
+	require.NoError(t, err)
+	require.Equal(t, &retryPolicy{5, 100 * time.Millisecond, time.Second, 0}, policy)
+
+	tests := []struct {
+		name string
+		conf *jsoncodec.ConfRetry
+	}{
+		{"MaxAttempts", &jsoncodec.ConfRetry{MaxAttempts: -1}},
+		{"Backoff", &jsoncodec.ConfRetry{Backoff: "soon"}},
+		{"MaxBackoff", &jsoncodec.ConfRetry{MaxBackoff: "-1s"}},
+		{"Jitter", &jsoncodec.ConfRetry{Jitter: &tooMuchJitter}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newRetryPolicy(tt.conf)
+			require.Error(t, err, "invalid settings should fail")
+		})
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := &retryPolicy{maxAttempts: 10, backoff: 100 * time.Millisecond, maxBackoff: time.Second}
+	var delays []time.Duration
+	for retry := 1; retry <= 6; retry++ {
+		delays = append(delays, policy.delay(retry))
+	}
+	require.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}, delays, "delay should double up to the max backoff")
+
+	policy.jitter = 0.5
+	for i := 0; i < 100; i++ {
+		delay := policy.delay(2)
+		require.True(t, delay > 100*time.Millisecond && delay <= 200*time.Millisecond, "jitter should only shorten the delay by up to half, got %s", delay)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"encoding/json"
+	"github.com/orbs-network/gamma-cli/jsoncodec"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// repeatable flag, eg. -set a=1 -set b=2
+type stringListFlag []string
+
+func (f *stringListFlag) String() string {
+	return strings.Join(*f, " ")
+}
+
+func (f *stringListFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// resolves ${NAME} in input files from -set, then -vars, then built-ins, then environment variables
+// a literal ${ in an input file is written $${, or the file is used as is with -no-template
+func configureTemplateVars() {
+	if *flagNoTemplate {
+		if len(flagSetVars) > 0 || *flagVarsFile != "" {
+			die("Options -set and -vars cannot be used with -no-template.")
+		}
+		jsoncodec.SetTemplateLookup(nil)
+		return
+	}
+
+	setVars := make(map[string]string)
+	for _, pair := range flagSetVars {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			die("Option -set should be of the form NAME=VALUE.\n\nCurrent value: '%s'", pair)
+		}
+		setVars[parts[0]] = parts[1]
+	}
+
+	fileVars := make(map[string]string)
+	if *flagVarsFile != "" {
+		vars, err := readTemplateVarsFile(*flagVarsFile)
+		if err != nil {
+			die("Failed parsing vars file '%s'.\n\n%s", *flagVarsFile, err.Error())
+		}
+		fileVars = vars
+	}
+
+	jsoncodec.SetTemplateLookup(func(name string) (string, bool, error) {
+		if value, found := setVars[name]; found {
+			return value, true, nil
+		}
+		if value, found := fileVars[name]; found {
+			return value, true, nil
+		}
+		if value, found, err := lookupBuiltinTemplateVar(name); found || err != nil {
+			return value, found, err
+		}
+		value, found := os.LookupEnv(name)
+		return value, found, nil
+	})
+}
+
+// a json object of names to values, non string values are used in their json form
+func readTemplateVarsFile(filename string) (map[string]string, error) {
+	bytes, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(bytes, &raw); err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]string)
+	for name, value := range raw {
+		var text string
+		if err := json.Unmarshal(value, &text); err == nil {
+			res[name] = text
+		} else {
+			res[name] = string(value)
+		}
+	}
+	return res, nil
+}
+
+// built-ins: ${now} (unix seconds), ${env} (-env) and ${keys.ID.Address|PublicKey|PrivateKey} from the test keys file
+func lookupBuiltinTemplateVar(name string) (string, bool, error) {
+	switch name {
+	case "now":
+		return strconv.FormatInt(time.Now().Unix(), 10), true, nil
+	case "env":
+		return *flagEnv, true, nil
+	}
+
+	if !strings.HasPrefix(name, "keys.") {
+		return "", false, nil
+	}
+	parts := strings.Split(name, ".")
+	if len(parts) != 3 {
+		return "", false, errors.Errorf("key variables should be of the form keys.ID.Address")
+	}
+
+	bytes, err := ioutil.ReadFile(*flagKeyFile)
+	if err != nil {
+		return "", false, errors.Wrapf(err, "could not open keys file '%s'", *flagKeyFile)
+	}
+	keys, err := jsoncodec.UnmarshalKeys(*flagKeyFile, bytes)
+	if err != nil {
+		return "", false, errors.Wrapf(err, "failed parsing keys file '%s'", *flagKeyFile)
+	}
+	key, found := keys[parts[1]]
+	if !found {
+		return "", false, errors.Errorf("key with id '%s' not found in key file '%s'", parts[1], *flagKeyFile)
+	}
+
+	switch parts[2] {
+	case "Address":
+		return key.Address, true, nil
+	case "PublicKey":
+		return key.PublicKey, true, nil
+	case "PrivateKey":
+		return key.PrivateKey, true, nil
+	}
+	return "", false, errors.Errorf("unknown key field '%s', should be Address, PublicKey or PrivateKey", parts[2])
+}
@@ -0,0 +1,49 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"github.com/orbs-network/gamma-cli/jsoncodec"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+)
+
+func TestConfigureTemplateVars_OptOut(t *testing.T) {
+	os.Setenv("GAMMA_TEST_TOKEN", "MyToken")
+	defer os.Unsetenv("GAMMA_TEST_TOKEN")
+	defer jsoncodec.SetTemplateLookup(nil)
+	input := []byte(`{"ContractName": "${GAMMA_TEST_TOKEN}", "MethodName": "price_in_$$${x}"}`)
+
+	tests := []struct {
+		name                 string
+		setVars              stringListFlag
+		noTemplate           bool
+		expectedContractName string
+		expectedMethodName   string
+	}{
+		{"Default", nil, false, "MyToken", "price_in_$${x}"},
+		{"SetVars", stringListFlag{"OTHER=1"}, false, "MyToken", "price_in_$${x}"},
+		{"NoTemplate", nil, true, "${GAMMA_TEST_TOKEN}", "price_in_$$${x}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flagSetVars = tt.setVars
+			*flagNoTemplate = tt.noTemplate
+			defer func() {
+				flagSetVars = nil
+				*flagNoTemplate = false
+			}()
+
+			configureTemplateVars()
+			sendTx, err := jsoncodec.UnmarshalSendTx("transfer.json", input)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedContractName, sendTx.ContractName)
+			require.Equal(t, tt.expectedMethodName, sendTx.MethodName)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"example.com/bundle/lib/dup"
+	"example.com/bundle/lib/math"
+	"github.com/orbs-network/orbs-contract-sdk/go/sdk/v1"
+)
+
+var PUBLIC = sdk.Export(add)
+var SYSTEM = sdk.Export(_init)
+
+func _init() {
+}
+
+func add(a uint64, b uint64) uint64 {
+	return dup.SafeAdd(a, b) + math.SafeAdd(a, b)
+}
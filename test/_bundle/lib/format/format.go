@@ -0,0 +1,13 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package format
+
+import "strconv"
+
+func Amount(value uint64) string {
+	return strconv.FormatUint(value, 10) + " tokens"
+}
@@ -0,0 +1,16 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+//go:build debug
+// +build debug
+
+package math
+
+// collides with add.go unless excluded by its build constraint
+func SafeAdd(a uint64, b uint64) uint64 {
+	println("SafeAdd", a, b)
+	return a + b
+}
@@ -0,0 +1,26 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"example.com/bundle/lib/math"
+	"github.com/orbs-network/orbs-contract-sdk/go/sdk/v1/state"
+)
+
+var SUPPLY_KEY = []byte("supply")
+
+func totalSupply() uint64 {
+	return state.ReadUint64(SUPPLY_KEY)
+}
+
+func mint(amount uint64) {
+	state.WriteUint64(SUPPLY_KEY, math.SafeAdd(totalSupply(), amount))
+}
+
+func burn(amount uint64) {
+	state.WriteUint64(SUPPLY_KEY, math.SafeSub(totalSupply(), amount))
+}
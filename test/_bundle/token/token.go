@@ -0,0 +1,24 @@
+// Copyright 2019 the gamma-cli authors
+// This file is part of the gamma-cli library in the Orbs project.
+//
+// This source code is licensed under the MIT license found in the LICENSE file in the root directory of this source tree.
+// The above notice should be included in all copies or substantial portions of the software.
+
+package main
+
+import (
+	"example.com/bundle/lib/format"
+	"github.com/orbs-network/orbs-contract-sdk/go/sdk/v1"
+	"github.com/orbs-network/orbs-contract-sdk/go/sdk/v1/state"
+)
+
+var PUBLIC = sdk.Export(mint, burn, describe)
+var SYSTEM = sdk.Export(_init)
+
+func _init() {
+	state.WriteUint64(SUPPLY_KEY, 0)
+}
+
+func describe() string {
+	return format.Amount(totalSupply())
+}